@@ -0,0 +1,51 @@
+// Command admin makes the user with the given uuid the first admin of the
+// service. The user is given by uuid rather than login, as a login belongs to
+// whoever signs up with it first. Roles from config are stored before, so the
+// command works on a fresh storage. Nothing is changed if there is an admin
+// already, further admins are assigned with AssignRole RPC
+package main
+
+import (
+	"Service/internal/config"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/services/rbac"
+	"Service/internal/storage/sqlite"
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+)
+
+var uuid = flag.Uint64("uuid", 0, "uuid of the user to make admin")
+
+func main() {
+	cfg := config.New()
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	if *uuid == 0 {
+		log.Error("uuid is required")
+		os.Exit(1)
+	}
+
+	st := sqlite.New(cfg.StoragePath)
+	roles := rbac.New(log, st, st)
+
+	ctx := context.Background()
+	if err := roles.SyncRoles(ctx, cfg.RBAC.Roles); err != nil {
+		log.Error("failed to sync roles", sl.Err(err))
+		os.Exit(1)
+	}
+
+	assigned, err := roles.Bootstrap(ctx, *uuid)
+	if err != nil {
+		log.Error("failed to bootstrap admin", sl.Err(err))
+		os.Exit(1)
+	}
+
+	if !assigned {
+		log.Warn("admin already exists, nothing is changed")
+		return
+	}
+
+	log.Info("admin is assigned", slog.Uint64("uuid", *uuid))
+}
@@ -0,0 +1,144 @@
+// Command import inserts users with already hashed passwords into storage of
+// the service. Users are read as JSON lines:
+//
+//	{"login": "...", "email": "...", "passwordHash": "$scrypt$...", "emailVerified": true}
+//
+// Hashes of every algorithm supported at login are accepted. They are
+// replaced with the current algorithm at the first login of the user
+package main
+
+import (
+	"Service/internal/config"
+	"Service/internal/domain/models"
+	"Service/internal/lib/identity"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/lib/password"
+	"Service/internal/storage/sqlite"
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+var (
+	inputPath = flag.String("input", "", "path to the JSON lines file with users, stdin if empty")
+	batchSize = flag.Int("batch-size", 500, "number of users inserted in one transaction")
+)
+
+type record struct {
+	Login         string `json:"login"`
+	Email         string `json:"email"`
+	PasswordHash  string `json:"passwordHash"`
+	EmailVerified bool   `json:"emailVerified"`
+}
+
+func main() {
+	cfg := config.New()
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	in := os.Stdin
+	if *inputPath != "" {
+		f, err := os.Open(*inputPath)
+		if err != nil {
+			log.Error("failed to open input", sl.Err(err))
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if err := run(context.Background(), log, sqlite.New(cfg.StoragePath), in, max(*batchSize, 1)); err != nil {
+		log.Error("failed to import users", sl.Err(err))
+		os.Exit(1)
+	}
+}
+
+// run reads users from r and imports them by batches. Invalid records and
+// users which already exist are reported and skipped
+func run(ctx context.Context, log *slog.Logger, st *sqlite.Storage, r io.Reader, batchSize int) error {
+	var imported, skipped, invalid int
+
+	batch := make([]models.User, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		exist, err := st.ImportUsers(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, login := range exist {
+			log.Warn("user already exists", slog.String("login", login))
+		}
+
+		imported += len(batch) - len(exist)
+		skipped += len(exist)
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		user, err := parseRecord(scanner.Bytes())
+		if err != nil {
+			log.Warn("invalid record", slog.Int("line", line), sl.Err(err))
+			invalid++
+			continue
+		}
+
+		batch = append(batch, user)
+		if len(batch) == batchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	log.Info(
+		"users are imported",
+		slog.Int("imported", imported),
+		slog.Int("skipped", skipped),
+		slog.Int("invalid", invalid),
+	)
+	return nil
+}
+
+func parseRecord(raw []byte) (models.User, error) {
+	var rec record
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return models.User{}, err
+	}
+
+	switch {
+	case rec.Login == "":
+		return models.User{}, fmt.Errorf("login is empty")
+	case identity.LooksLikeEmail(rec.Login):
+		return models.User{}, fmt.Errorf("login must not contain '@'")
+	case rec.Email == "":
+		return models.User{}, fmt.Errorf("email is empty")
+	case !password.Known([]byte(rec.PasswordHash)):
+		return models.User{}, fmt.Errorf("unsupported password hash")
+	}
+
+	return models.User{
+		Login:         rec.Login,
+		Email:         rec.Email,
+		PassHash:      []byte(rec.PasswordHash),
+		EmailVerified: rec.EmailVerified,
+	}, nil
+}
@@ -0,0 +1,36 @@
+// Command secret generates a secret of a confidential OAuth client. The
+// secret is printed once to be handed to the client, its hash is put into
+// `secret-hash` of the client in config
+package main
+
+import (
+	"Service/internal/config"
+	"Service/internal/lib/opaque"
+	"Service/internal/lib/password"
+	"fmt"
+	"os"
+)
+
+func main() {
+	cfg := config.New()
+
+	hasher, err := password.NewHasher(password.Argon2idParams(cfg.Password.Argon2id))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create hasher:", err)
+		os.Exit(1)
+	}
+
+	secret, err := opaque.NewToken()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to generate secret:", err)
+		os.Exit(1)
+	}
+
+	hash, err := hasher.Hash(secret)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to hash secret:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("secret: %s\nsecret-hash: %q\n", secret, hash)
+}
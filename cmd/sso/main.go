@@ -22,24 +22,26 @@ func main() {
 
 	log.Info("logger set up", slog.Any("cfg", cfg))
 
-	application := app.New(
-		log,
-		cfg.StoragePath,
-		cfg.Secret,
-		cfg.TokenTTL,
-		cfg.RefreshTTL,
-		cfg.GRPC.Port,
-		cfg.GRPC.Timeout,
-	)
+	application := app.New(log, cfg)
 
 	go application.GRPCApp.MustRun()
+	go application.HTTPApp.MustRun()
+	if application.MetricsApp != nil {
+		go application.MetricsApp.MustRun()
+	}
+	application.Janitor.Start()
 
-	stop := make(chan os.Signal)
+	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
 	sign := <-stop
 	log.Info("receive signal", slog.Any("signal", sign))
 	application.GRPCApp.Stop()
+	application.Janitor.Stop()
+	application.HTTPApp.Stop()
+	if application.MetricsApp != nil {
+		application.MetricsApp.Stop()
+	}
 }
 
 // setUpLogger returns set logger according to current environment
@@ -2,36 +2,308 @@ package app
 
 import (
 	grpcapp "Service/internal/app/grpc"
+	httpapp "Service/internal/app/http"
+	metricsapp "Service/internal/app/metrics"
+	"Service/internal/config"
+	"Service/internal/domain/models"
+	"Service/internal/grpc/authz"
+	"Service/internal/grpc/ratelimit"
+	"Service/internal/lib/events"
+	"Service/internal/lib/jwt"
+	"Service/internal/lib/notify"
+	"Service/internal/lib/password"
 	"Service/internal/services/auth"
 	"Service/internal/services/follow"
+	"Service/internal/services/janitor"
+	"Service/internal/services/oauth"
+	"Service/internal/services/passpolicy"
+	"Service/internal/services/rbac"
+	"Service/internal/services/revocation"
 	"Service/internal/services/userinfo"
 	"Service/internal/storage/sqlite"
+	"context"
+	"errors"
 	"log/slog"
-	"time"
+	"strconv"
 )
 
+// envLocal is the environment where development signing keys are allowed
+const envLocal = "local"
+
 type App struct {
 	GRPCApp *grpcapp.App
+	HTTPApp *httpapp.App
+	// MetricsApp is nil if metrics are disabled
+	MetricsApp *metricsapp.App
+	Janitor    *janitor.Janitor
 }
 
 func New(
 	log *slog.Logger,
-	storagePath string,
-	secret string,
-	tokenTTL time.Duration,
-	refreshTTL time.Duration,
-	port int,
-	timeout time.Duration,
+	cfg *config.Config,
 ) *App {
 
-	st := sqlite.New(storagePath)
+	st := sqlite.New(cfg.StoragePath)
+	reportIdentityCollisions(log, st)
+	keys := mustLoadKeyRing(cfg)
+
+	tokens := &jwt.Issuer{
+		Keys:      keys,
+		Name:      cfg.Issuer,
+		Audiences: cfg.Audiences,
+		Leeway:    cfg.Leeway,
+	}
+
+	revoked, err := revocation.New(context.Background(), log, st, cfg.Leeway)
+	if err != nil {
+		panic("failed to load revocation list: " + err.Error())
+	}
 
-	authsrvc := auth.New(log, st, st, st, secret, tokenTTL, refreshTTL)
+	authsrvc := auth.New(log, auth.Deps{
+		UserProvider:      st,
+		UserSaver:         st,
+		TokenProvider:     st,
+		ResetStore:        st,
+		VerificationStore: st,
+		MFAStore:          st,
+		AttemptStore:      st,
+		Events:            events.NewLog(log),
+		Revoked:           revoked,
+		Notifier:          mustCreateNotifier(cfg),
+		Tokens:            tokens,
+		Passwords:         mustCreatePasswordHasher(cfg),
+		PasswordPolicy:    mustCreatePasswordPolicy(cfg),
+		TokenTTL:          cfg.TokenTTL,
+		RefreshTTL:        cfg.RefreshTTL,
+		ResetTTL:          cfg.ResetTTL,
+		ResetInterval:     cfg.ResetInterval,
+		Verification: auth.VerificationPolicy{
+			TTL:            cfg.Verification.TTL,
+			ResendInterval: cfg.Verification.ResendInterval,
+			Required:       cfg.Verification.Required,
+		},
+		MFA: auth.MFAPolicy{
+			Issuer:       cfg.MFA.Issuer,
+			ChallengeTTL: cfg.MFA.ChallengeTTL,
+		},
+		Lockout: auth.LockoutPolicy{
+			Login:  auth.LockoutLimit(cfg.Lockout.Login),
+			IP:     auth.LockoutLimit(cfg.Lockout.IP),
+			Window: cfg.Lockout.Window,
+		},
+	})
 	usrInfo := userinfo.New(log, st)
 	fllw := follow.New(log, st, st, st)
-	gRPCApp := grpcapp.New(log, port, timeout, authsrvc, usrInfo, fllw)
+	roles := rbac.New(log, st, st)
+	mustSetUpRoles(log, cfg, roles)
+	authorizer := authz.New(log, tokens, revoked, grpcapp.Policy())
+	limiter := newRateLimiter(log, cfg)
+	gRPCApp := grpcapp.New(log, cfg.GRPC.Port, cfg.GRPC.Timeout, authsrvc, usrInfo, fllw, roles, authorizer, limiter)
+	oauthsrvc := mustCreateOAuth(log, cfg, st, authsrvc, tokens)
+	httpApp := httpapp.New(
+		log,
+		cfg.HTTP.Port,
+		cfg.HTTP.Timeout,
+		cfg.Issuer,
+		keys,
+		oauthsrvc,
+		authsrvc,
+		tokens,
+		revoked,
+		usrInfo,
+	)
+
+	var metricsApp *metricsapp.App
+	if cfg.Metrics.Addr != "" {
+		metricsApp = metricsapp.New(log, cfg.Metrics.Addr, cfg.HTTP.Timeout)
+	}
 
 	return &App{
-		GRPCApp: gRPCApp,
+		GRPCApp:    gRPCApp,
+		HTTPApp:    httpApp,
+		MetricsApp: metricsApp,
+		Janitor:    mustCreateJanitor(log, cfg, st),
+	}
+}
+
+// mustLoadKeyRing loads signing keys listed in config. Superseded keys are
+// kept while tokens signed with them can be alive
+func mustLoadKeyRing(cfg *config.Config) *jwt.KeyRing {
+	keys := make([]*jwt.Key, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		if k.Dev {
+			if cfg.Env != envLocal {
+				panic("development signing key " + strconv.Quote(k.ID) + " is not allowed in " + cfg.Env + " environment")
+			}
+
+			if err := jwt.GenerateKey(k.Path); err != nil {
+				panic("failed to generate signing key: " + err.Error())
+			}
+		}
+
+		key, err := jwt.LoadKey(k.ID, k.Path, k.ActiveFrom)
+		if err != nil {
+			panic("failed to load signing key: " + err.Error())
+		}
+
+		keys = append(keys, key)
+	}
+
+	ring, err := jwt.NewKeyRing(max(cfg.TokenTTL, cfg.RefreshTTL), keys...)
+	if err != nil {
+		panic("failed to create key ring: " + err.Error())
+	}
+
+	if _, err = ring.SigningKey(); err != nil {
+		panic("failed to create key ring: " + err.Error())
+	}
+
+	return ring
+}
+
+// mustCreateJanitor creates janitor purging storage with settings of config
+func mustCreateJanitor(log *slog.Logger, cfg *config.Config, st *sqlite.Storage) *janitor.Janitor {
+	j, err := janitor.New(log, st, cfg.Janitor.Interval, cfg.Janitor.BatchSize)
+	if err != nil {
+		panic("failed to create janitor: " + err.Error())
+	}
+
+	return j
+}
+
+// mustCreateNotifier creates notifier configured in config
+func mustCreateNotifier(cfg *config.Config) *notify.Writer {
+	if cfg.Notifier.Path == "" {
+		return notify.NewStdout()
+	}
+
+	notifier, err := notify.NewFile(cfg.Notifier.Path)
+	if err != nil {
+		panic("failed to create notifier: " + err.Error())
+	}
+
+	return notifier
+}
+
+// reportIdentityCollisions logs users whose login or email matches another
+// user after normalization. They have to be resolved by hand
+func reportIdentityCollisions(log *slog.Logger, st *sqlite.Storage) {
+	collisions, err := st.IdentityCollisions(context.Background())
+	if err != nil {
+		panic("failed to find identity collisions: " + err.Error())
+	}
+
+	for _, c := range collisions {
+		log.Warn(
+			"identifier collides with another user, only exact value matches",
+			slog.Uint64("uuid", c.UUID),
+			slog.String("field", c.Field),
+			slog.String("value", c.Value),
+			slog.Uint64("owner", c.Owner),
+		)
+	}
+}
+
+// mustSetUpRoles stores roles configured in config and assigns admin role to
+// the bootstrap admin if there is no admin yet
+func mustSetUpRoles(log *slog.Logger, cfg *config.Config, roles *rbac.RBAC) {
+	ctx := context.Background()
+	if err := roles.SyncRoles(ctx, cfg.RBAC.Roles); err != nil {
+		panic("failed to sync roles: " + err.Error())
+	}
+
+	if cfg.RBAC.BootstrapAdmin == 0 {
+		return
+	}
+
+	if _, err := roles.Bootstrap(ctx, cfg.RBAC.BootstrapAdmin); err != nil {
+		if errors.Is(err, rbac.ErrUserNotFound) {
+			log.Warn("bootstrap admin is not found", slog.Uint64("uuid", cfg.RBAC.BootstrapAdmin))
+			return
+		}
+
+		panic("failed to bootstrap admin: " + err.Error())
+	}
+}
+
+// mustCreateOAuth creates authorization server of clients registered in config
+func mustCreateOAuth(
+	log *slog.Logger,
+	cfg *config.Config,
+	st *sqlite.Storage,
+	sessions oauth.SessionProvider,
+	tokens *jwt.Issuer,
+) *oauth.OAuth {
+	clients := make([]models.OAuthClient, 0, len(cfg.OAuth.Clients))
+	for _, c := range cfg.OAuth.Clients {
+		clients = append(clients, models.OAuthClient(c))
+	}
+
+	srvc, err := oauth.New(
+		log,
+		clients,
+		st,
+		st,
+		sessions,
+		mustCreatePasswordHasher(cfg),
+		tokens,
+		cfg.OAuth.CodeTTL,
+		cfg.TokenTTL,
+	)
+	if err != nil {
+		panic("failed to create oauth server: " + err.Error())
+	}
+
+	return srvc
+}
+
+// mustCreatePasswordHasher creates hasher with argon2id parameters from config
+func mustCreatePasswordHasher(cfg *config.Config) *password.Hasher {
+	hasher, err := password.NewHasher(password.Argon2idParams(cfg.Password.Argon2id))
+	if err != nil {
+		panic("failed to create password hasher: " + err.Error())
+	}
+
+	return hasher
+}
+
+// mustCreatePasswordPolicy creates policy of new passwords configured in config
+func mustCreatePasswordPolicy(cfg *config.Config) *passpolicy.Policy {
+	policy, err := passpolicy.New(passpolicy.Rules(cfg.Password.Policy))
+	if err != nil {
+		panic("failed to create password policy: " + err.Error())
+	}
+
+	return policy
+}
+
+// newRateLimiter creates limiter of RPCs configured in config. Subject of the
+// request is its authenticated principal
+func newRateLimiter(log *slog.Logger, cfg *config.Config) *ratelimit.Limiter {
+	methods := make(map[string]ratelimit.MethodLimits, len(cfg.RateLimit.Methods))
+	for name, m := range cfg.RateLimit.Methods {
+		methods[name] = methodLimits(m)
+	}
+
+	subject := func(ctx context.Context) (string, bool) {
+		principal, ok := authz.PrincipalFrom(ctx)
+		if !ok {
+			return "", false
+		}
+
+		if principal.Client {
+			return "client:" + principal.ClientID, true
+		}
+
+		return strconv.FormatUint(principal.UUID, 10), true
+	}
+
+	return ratelimit.New(log, methodLimits(cfg.RateLimit.Default), methods, subject)
+}
+
+func methodLimits(m config.RateLimitMethodObj) ratelimit.MethodLimits {
+	return ratelimit.MethodLimits{
+		PerIP:      ratelimit.Limit(m.PerIP),
+		PerSubject: ratelimit.Limit(m.PerSubject),
 	}
 }
@@ -3,12 +3,16 @@ package grpcapp
 import (
 	"Service/internal/domain/models"
 	grpcauth "Service/internal/grpc/auth"
+	"Service/internal/grpc/authz"
 	grpcfollow "Service/internal/grpc/follow"
+	"Service/internal/grpc/ratelimit"
+	grpcrbac "Service/internal/grpc/rbac"
 	grpcusrinfo "Service/internal/grpc/userinfo"
 	"Service/internal/lib/logger/sl"
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
 	"time"
 
@@ -29,15 +33,71 @@ type Auth interface {
 	Login(
 		ctx context.Context,
 		login, password string,
+		device models.Device,
 	) (models.TokensPair, error)
 	SignUp(
 		ctx context.Context,
 		login, email, password string,
+		device models.Device,
 	) (models.TokensPair, error)
 	UpdateTokens(
 		ctx context.Context,
 		refreshToken string,
 	) (models.TokensPair, error)
+	Introspect(
+		ctx context.Context,
+		accessToken string,
+	) (models.TokenInfo, error)
+	Logout(
+		ctx context.Context,
+		refreshToken string,
+	) error
+	LogoutAll(
+		ctx context.Context,
+		uuid uint64,
+	) error
+	Sessions(
+		ctx context.Context,
+		uuid uint64,
+	) ([]models.Session, error)
+	RevokeSession(
+		ctx context.Context,
+		uuid uint64,
+		sessionID string,
+	) error
+	ChangePassword(
+		ctx context.Context,
+		accessToken, oldPassword, newPassword string,
+	) error
+	RequestPasswordReset(
+		ctx context.Context,
+		email string,
+	) error
+	ConfirmPasswordReset(
+		ctx context.Context,
+		token, newPassword string,
+	) error
+	VerifyEmail(
+		ctx context.Context,
+		code string,
+	) error
+	ResendVerification(
+		ctx context.Context,
+		email string,
+	) error
+	EnrollTOTP(
+		ctx context.Context,
+		accessToken string,
+	) (string, string, error)
+	ConfirmTOTP(
+		ctx context.Context,
+		accessToken, code string,
+	) ([]string, error)
+	CompleteLogin(
+		ctx context.Context,
+		mfaToken, code string,
+		device models.Device,
+	) (models.TokensPair, error)
 }
 
 type UserInfo interface {
@@ -47,6 +107,12 @@ type UserInfo interface {
 	UsersByLogin(ctx context.Context, login string) ([]models.User, error)
 }
 
+type RBAC interface {
+	AssignRole(ctx context.Context, actor, uuid uint64, role string) error
+	RevokeRole(ctx context.Context, actor, uuid uint64, role string) error
+	Roles(ctx context.Context, actor, uuid uint64) ([]string, []string, error)
+}
+
 type FollowProvider interface {
 	Follow(
 		ctx context.Context,
@@ -74,6 +140,9 @@ func New(
 	auth Auth,
 	usrInfo UserInfo,
 	followProvider FollowProvider,
+	rbac RBAC,
+	authorizer *authz.Authorizer,
+	limiter *ratelimit.Limiter,
 ) *App {
 	recoveryOpts := []recovery.Option{
 		recovery.WithRecoveryHandler(
@@ -93,6 +162,9 @@ func New(
 	grpcsrv := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			recovery.UnaryServerInterceptor(recoveryOpts...),
+			limiter.PerIPInterceptor(),
+			authorizer.UnaryServerInterceptor(),
+			limiter.PerSubjectInterceptor(),
 			// this part is necessary for logging.
 			// I commented it because logs are to large and unreadable
 			//
@@ -106,6 +178,7 @@ func New(
 	grpcauth.Register(grpcsrv, auth)
 	grpcusrinfo.Register(grpcsrv, usrInfo)
 	grpcfollow.Register(grpcsrv, followProvider)
+	grpcrbac.Register(grpcsrv, rbac)
 
 	return &App{
 		log:     log,
@@ -114,6 +187,16 @@ func New(
 	}
 }
 
+// Policy returns access policy of all RPCs served by the application
+func Policy() map[string]authz.Rule {
+	policy := grpcauth.Policy()
+	maps.Copy(policy, grpcusrinfo.Policy())
+	maps.Copy(policy, grpcfollow.Policy())
+	maps.Copy(policy, grpcrbac.Policy())
+
+	return policy
+}
+
 // logInterceptor wraps my logger to logging.Logger type
 func logInterceptor(log *slog.Logger) logging.Logger {
 	return logging.LoggerFunc(
@@ -145,8 +228,16 @@ func (a *App) Run() error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	return a.Serve(lis)
+}
+
+// Serve serves gRPC requests accepted by the listener
+func (a *App) Serve(lis net.Listener) error {
+	const op = "grpcapp.Serve"
+	log := a.log.With(slog.String("op", op))
+
 	log.Info("starting to serve", slog.String("address", lis.Addr().String()))
-	if err = a.gRPCSrv.Serve(lis); err != nil {
+	if err := a.gRPCSrv.Serve(lis); err != nil {
 		log.Error("failed to serve socket", sl.Err(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -0,0 +1,105 @@
+package httpapp
+
+import (
+	httpoauth "Service/internal/http/oauth"
+	httpuserinfo "Service/internal/http/userinfo"
+	"Service/internal/http/wellknown"
+	"Service/internal/lib/logger/sl"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+type App struct {
+	log     *slog.Logger
+	httpSrv *http.Server
+	port    int
+	timeout time.Duration
+}
+
+// New creates HTTP application serving OAuth 2.0 and OpenID Connect
+// endpoints and public documents of the service
+func New(
+	log *slog.Logger,
+	port int,
+	timeout time.Duration,
+	issuer string,
+	keys wellknown.KeyProvider,
+	oauth httpoauth.OAuth,
+	authenticator httpoauth.Authenticator,
+	tokens httpuserinfo.TokenParser,
+	revoked httpuserinfo.RevocationList,
+	usrPrv httpuserinfo.UserProvider,
+) *App {
+	mux := http.NewServeMux()
+
+	wellknown.Register(mux, issuer, keys)
+	httpoauth.Register(mux, log, oauth, authenticator)
+	httpuserinfo.Register(mux, log, tokens, revoked, usrPrv)
+
+	return &App{
+		log: log,
+		httpSrv: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: timeout,
+			ReadTimeout:       timeout,
+			WriteTimeout:      timeout,
+		},
+		port:    port,
+		timeout: timeout,
+	}
+}
+
+// Handler returns handler of all endpoints of the application
+func (a *App) Handler() http.Handler {
+	return a.httpSrv.Handler
+}
+
+// MustRun is wrapper of Run function which panics when error occurred
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil {
+		panic("failed to run http application" + err.Error())
+	}
+}
+
+// Run runs application
+func (a *App) Run() error {
+	const op = "httpapp.Run"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting HTTP application")
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
+	if err != nil {
+		log.Error(
+			"failed to listen addr",
+			sl.Err(err),
+			slog.Int("port", a.port))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("starting to serve", slog.String("address", lis.Addr().String()))
+	if err = a.httpSrv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("failed to serve socket", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Stop is graceful shutdown for application
+func (a *App) Stop() {
+	const op = "httpapp.Stop"
+	log := a.log.With(slog.String("op", op))
+	log.Info("stopping application")
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	if err := a.httpSrv.Shutdown(ctx); err != nil {
+		log.Error("failed to shutdown server", sl.Err(err))
+	}
+}
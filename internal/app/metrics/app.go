@@ -0,0 +1,83 @@
+package metricsapp
+
+import (
+	"Service/internal/lib/logger/sl"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// App serves runtime metrics of the service. It listens on its own address,
+// so metrics are not exposed on the public HTTP port
+type App struct {
+	log     *slog.Logger
+	httpSrv *http.Server
+	addr    string
+	timeout time.Duration
+}
+
+// New creates application serving expvar metrics on addr, e.g.
+// "127.0.0.1:20204"
+func New(log *slog.Logger, addr string, timeout time.Duration) *App {
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/vars", expvar.Handler())
+
+	return &App{
+		log: log,
+		httpSrv: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: timeout,
+			ReadTimeout:       timeout,
+			WriteTimeout:      timeout,
+		},
+		addr:    addr,
+		timeout: timeout,
+	}
+}
+
+// MustRun is wrapper of Run function which panics when error occurred
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil {
+		panic("failed to run metrics application" + err.Error())
+	}
+}
+
+// Run runs application
+func (a *App) Run() error {
+	const op = "metricsapp.Run"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting metrics application")
+
+	lis, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		log.Error("failed to listen addr", sl.Err(err), slog.String("addr", a.addr))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("starting to serve", slog.String("address", lis.Addr().String()))
+	if err = a.httpSrv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("failed to serve socket", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Stop is graceful shutdown for application
+func (a *App) Stop() {
+	const op = "metricsapp.Stop"
+	log := a.log.With(slog.String("op", op))
+	log.Info("stopping application")
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	if err := a.httpSrv.Shutdown(ctx); err != nil {
+		log.Error("failed to shutdown server", sl.Err(err))
+	}
+}
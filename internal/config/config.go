@@ -8,12 +8,39 @@ import (
 )
 
 type Config struct {
-	Env         string        `yaml:"env" env-default:"prod"`
-	StoragePath string        `yaml:"storage-path" env-required:"true"`
-	Secret      string        `yaml:"secret" env-required:"true"`
-	TokenTTL    time.Duration `yaml:"tokenTTL" env-default:"30m"`
-	RefreshTTL  time.Duration `yaml:"refreshTTL" env-default:"7d"`
-	GRPC        GRPCObj       `yaml:"grpc"`
+	Env           string          `yaml:"env" env-default:"prod"`
+	StoragePath   string          `yaml:"storage-path" env-required:"true"`
+	Keys          []KeyObj        `yaml:"keys" env-required:"true"`
+	TokenTTL      time.Duration   `yaml:"tokenTTL" env-default:"30m"`
+	RefreshTTL    time.Duration   `yaml:"refreshTTL" env-default:"7d"`
+	ResetTTL      time.Duration   `yaml:"resetTTL" env-default:"15m"`
+	ResetInterval time.Duration   `yaml:"reset-interval" env-default:"1m"`
+	Issuer        string          `yaml:"issuer" env-default:"http://localhost:20203"`
+	Audiences     []string        `yaml:"audiences"`
+	Leeway        time.Duration   `yaml:"leeway" env-default:"30s"`
+	GRPC          GRPCObj         `yaml:"grpc"`
+	HTTP          HTTPObj         `yaml:"http"`
+	Metrics       MetricsObj      `yaml:"metrics"`
+	Janitor       JanitorObj      `yaml:"janitor"`
+	Notifier      NotifierObj     `yaml:"notifier"`
+	Verification  VerificationObj `yaml:"verification"`
+	MFA           MFAObj          `yaml:"mfa"`
+	Lockout       LockoutObj      `yaml:"lockout"`
+	RateLimit     RateLimitObj    `yaml:"rate-limit"`
+	RBAC          RBACObj         `yaml:"rbac"`
+	OAuth         OAuthObj        `yaml:"oauth"`
+	Password      PasswordObj     `yaml:"password"`
+}
+
+// KeyObj describes private key used to sign tokens. The key starts to sign
+// new tokens since ActiveFrom, so rotation can be scheduled in advance
+type KeyObj struct {
+	ID         string    `yaml:"id"`
+	Path       string    `yaml:"path"`
+	ActiveFrom time.Time `yaml:"active-from"`
+	// Dev key is generated at startup if the file is missing. It is refused
+	// in environments other than local
+	Dev bool `yaml:"dev"`
 }
 
 type GRPCObj struct {
@@ -21,6 +48,138 @@ type GRPCObj struct {
 	Timeout time.Duration `yaml:"timeout" env-default:"5s"`
 }
 
+var (
+	configPath = flag.String("config", "", "path to the config file")
+)
+
+type HTTPObj struct {
+	Port    int           `yaml:"port" env-default:"20203"`
+	Timeout time.Duration `yaml:"timeout" env-default:"5s"`
+}
+
+// MetricsObj configures listener of runtime metrics. Metrics are not served
+// if Addr is empty, it should not be reachable from the public network
+type MetricsObj struct {
+	Addr string `yaml:"addr"`
+}
+
+// JanitorObj configures purging of expired and revoked tokens from storage
+type JanitorObj struct {
+	Interval  time.Duration `yaml:"interval" env-default:"10m"`
+	BatchSize int           `yaml:"batch-size" env-default:"500"`
+}
+
+// NotifierObj configures delivery of notifications. They are appended to the
+// file at Path, or written to stdout if Path is empty
+type NotifierObj struct {
+	Path string `yaml:"path"`
+}
+
+// RBACObj configures roles with their permissions. BootstrapAdmin is uuid of
+// the user made admin at startup if there is no admin yet, zero means none
+type RBACObj struct {
+	Roles          map[string][]string `yaml:"roles"`
+	BootstrapAdmin uint64              `yaml:"bootstrap-admin"`
+}
+
+// OAuthObj configures OAuth 2.0 authorization server. Clients without secret
+// hash are public clients proving possession of authorization codes with PKCE.
+// Grants of clients default to authorization code and refresh token
+type OAuthObj struct {
+	CodeTTL time.Duration    `yaml:"code-ttl" env-default:"1m"`
+	Clients []OAuthClientObj `yaml:"clients"`
+}
+
+type OAuthClientObj struct {
+	ID           string   `yaml:"id"`
+	Name         string   `yaml:"name"`
+	RedirectURIs []string `yaml:"redirect-uris"`
+	Scopes       []string `yaml:"scopes"`
+	Grants       []string `yaml:"grants"`
+	SecretHash   string   `yaml:"secret-hash"`
+}
+
+// VerificationObj configures verification of user emails. If Required is set,
+// users with unverified email are not able to log in
+type VerificationObj struct {
+	TTL            time.Duration `yaml:"ttl" env-default:"24h"`
+	ResendInterval time.Duration `yaml:"resend-interval" env-default:"1m"`
+	Required       bool          `yaml:"required" env-default:"false"`
+}
+
+// MFAObj configures second factor of authentication
+type MFAObj struct {
+	Issuer       string        `yaml:"issuer" env-default:"SSO"`
+	ChallengeTTL time.Duration `yaml:"challenge-ttl" env-default:"5m"`
+}
+
+// LockoutObj configures lockout of logins and client IPs after failed logins
+type LockoutObj struct {
+	Login  LockoutLimitObj `yaml:"login"`
+	IP     LockoutLimitObj `yaml:"ip"`
+	Window time.Duration   `yaml:"window" env-default:"15m"`
+}
+
+// LockoutLimitObj describes lockout of a single key. After Threshold failures
+// the key is locked for BaseDelay, every next failure doubles the delay up to
+// MaxDelay
+type LockoutLimitObj struct {
+	Threshold int           `yaml:"threshold"`
+	BaseDelay time.Duration `yaml:"base-delay" env-default:"30s"`
+	MaxDelay  time.Duration `yaml:"max-delay" env-default:"1h"`
+}
+
+// RateLimitObj configures rate limits of RPCs. Methods are keyed by full
+// method name, e.g. "/auth.Auth/SignUp", other methods use Default limits
+type RateLimitObj struct {
+	Default RateLimitMethodObj            `yaml:"default"`
+	Methods map[string]RateLimitMethodObj `yaml:"methods"`
+}
+
+// RateLimitMethodObj describes limits of a single RPC per client IP and per
+// authenticated subject
+type RateLimitMethodObj struct {
+	PerIP      RateLimitBucketObj `yaml:"per-ip"`
+	PerSubject RateLimitBucketObj `yaml:"per-subject"`
+}
+
+// RateLimitBucketObj is a token bucket refilled with one token every Every
+// and holding at most Burst tokens. Zero Every disables the limit
+type RateLimitBucketObj struct {
+	Every time.Duration `yaml:"every"`
+	Burst int           `yaml:"burst"`
+}
+
+// PasswordObj configures hashing of passwords. New hashes are made with
+// argon2id, hashes with other parameters are replaced at login
+type PasswordObj struct {
+	Argon2id Argon2idObj       `yaml:"argon2id"`
+	Policy   PasswordPolicyObj `yaml:"policy"`
+}
+
+// Argon2idObj holds argon2id parameters, Memory is set in KiB
+type Argon2idObj struct {
+	Memory      uint32 `yaml:"memory" env-default:"19456"`
+	Iterations  uint32 `yaml:"iterations" env-default:"2"`
+	Parallelism uint8  `yaml:"parallelism" env-default:"1"`
+	SaltLength  uint32 `yaml:"salt-length" env-default:"16"`
+	KeyLength   uint32 `yaml:"key-length" env-default:"32"`
+}
+
+// PasswordPolicyObj configures rules of new passwords. MinLength is counted in
+// characters, MaxLength in bytes. BreachedList is path to the file with
+// breached passwords, one per line
+type PasswordPolicyObj struct {
+	MinLength      int    `yaml:"min-length" env-default:"8"`
+	MaxLength      int    `yaml:"max-length" env-default:"72"`
+	RequireLower   bool   `yaml:"require-lower"`
+	RequireUpper   bool   `yaml:"require-upper"`
+	RequireDigit   bool   `yaml:"require-digit"`
+	RequireSymbol  bool   `yaml:"require-symbol"`
+	RejectIdentity bool   `yaml:"reject-identity" env-default:"true"`
+	BreachedList   string `yaml:"breached-list"`
+}
+
 const (
 	defaultConfigPath = "/home/il/Pet-project/SSO/SSO_Service/config/config.yml"
 )
@@ -47,15 +206,14 @@ func New() *Config {
 //
 // flag > env > default
 func fetchConfigPath() string {
-	res := ""
-
-	flag.StringVar(&res, "config", "", "path to the config file")
-	flag.Parse()
-	if res != "" {
-		return res
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	if *configPath != "" {
+		return *configPath
 	}
 
-	res = os.Getenv("CONFIG_PATH")
+	res := os.Getenv("CONFIG_PATH")
 	if res != "" {
 		return res
 	}
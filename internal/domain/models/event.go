@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+const (
+	EventRefreshTokenReuse = "refresh_token_reuse"
+)
+
+// SecurityEvent is a notable event related to account security
+type SecurityEvent struct {
+	Type      string
+	UserID    uint64
+	SessionID string
+	Time      time.Time
+}
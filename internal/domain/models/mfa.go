@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TOTP is the TOTP second factor of the user. The factor is used at login
+// only after enrollment is confirmed
+type TOTP struct {
+	UserID    uint64
+	Secret    string
+	Confirmed bool
+	// LastStep is the time step of the last accepted code
+	LastStep int64
+}
+
+// MFAChallenge is a stored challenge of two-step login. It is issued after
+// correct password and completed with a second factor code. Only hash of the
+// challenge token is kept
+type MFAChallenge struct {
+	Hash      []byte
+	UserID    uint64
+	ExpiresAt time.Time
+	Attempts  int
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	NotificationPasswordReset     = "password_reset"
+	NotificationEmailVerification = "email_verification"
+)
+
+// Notification is a message delivered to the user out of band
+type Notification struct {
+	Kind    string
+	To      string
+	Subject string
+	Body    string
+	// Secret is the one-time secret the message carries, e.g. reset token
+	Secret string
+	Time   time.Time
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// OAuthClient is an application registered to obtain tokens on behalf of
+// users or on its own behalf. Confidential clients authenticate with the
+// secret, only PHC hash of it is kept
+type OAuthClient struct {
+	ID           string
+	Name         string
+	RedirectURIs []string
+	Scopes       []string
+	Grants       []string
+	SecretHash   string
+}
+
+// AuthorizationRequest is a request of the client to authorize it on behalf
+// of the user (RFC 6749, RFC 7636)
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scopes              []string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+}
+
+// AuthorizationCode is a stored one-time code exchanged for tokens by the
+// client. Only hash of the code is kept
+type AuthorizationCode struct {
+	Hash          []byte
+	ClientID      string
+	UserID        uint64
+	RedirectURI   string
+	Scopes        []string
+	CodeChallenge string
+	Nonce         string
+	AuthTime      time.Time
+	Device        Device
+	ExpiresAt     time.Time
+}
+
+// Grant is an access the user has granted to the client. Tokens of first
+// party logins have empty grant
+type Grant struct {
+	ClientID string
+	Scopes   []string
+}
+
+// OAuthTokens is a response of the token endpoint
+type OAuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	ExpiresIn    time.Duration
+	Scopes       []string
+}
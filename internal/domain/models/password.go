@@ -0,0 +1,8 @@
+package models
+
+// PasswordViolation describes a rule of password policy broken by a password.
+// Rule is a stable machine readable code, Message is meant for people
+type PasswordViolation struct {
+	Rule    string
+	Message string
+}
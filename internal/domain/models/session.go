@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Device describes the client a session is started from
+type Device struct {
+	IP        string
+	UserAgent string
+}
+
+// Session is a login session of the user. Refresh tokens of the session are
+// rotated within it
+type Session struct {
+	ID              string
+	UserID          uint64
+	Device          Device
+	CreatedAt       time.Time
+	LastRefreshedAt time.Time
+}
+
+// LoginAttempts is a counter of failed logins made with the same key, e.g.
+// login or client IP
+type LoginAttempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 const (
 	RefreshToken = iota
 	AccessToken
@@ -14,3 +16,55 @@ type TokensPair struct {
 	RefreshToken Token
 	AccessToken  Token
 }
+
+// TrackedToken is a refresh token tracked by storage. Only hash of the token
+// is kept
+type TrackedToken struct {
+	Hash      []byte
+	UserID    uint64
+	SessionID string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	Used      bool
+	Revoked   bool
+	// Access is the access token issued together with the refresh token
+	Access AccessTokenRef
+	// Grant is the access of the client the token is issued to
+	Grant Grant
+}
+
+// TokenInfo describes access token according to RFC 7662
+type TokenInfo struct {
+	Active    bool
+	UUID      uint64
+	Login     string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	Scopes    []string
+	Roles     []string
+	ClientID  string
+}
+
+// AccessTokenRef identifies issued access token by its jti
+type AccessTokenRef struct {
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// ResetToken is a stored one-time password reset token. Only hash of the
+// token is kept
+type ResetToken struct {
+	Hash      []byte
+	UserID    uint64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// EmailVerification is a stored code confirming ownership of the email. Only
+// hash of the code is kept
+type EmailVerification struct {
+	Hash      []byte
+	UserID    uint64
+	SentAt    time.Time
+	ExpiresAt time.Time
+}
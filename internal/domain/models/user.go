@@ -5,4 +5,18 @@ type User struct {
 	Login    string
 	Email    string
 	PassHash []byte
+	// EmailVerified reports whether the user confirmed ownership of the email
+	EmailVerified bool
+}
+
+// IdentityCollision is a user whose login or email matches identifier of
+// another user after normalization. Such users are found only by exact value
+// of the identifier until the collision is resolved
+type IdentityCollision struct {
+	UUID uint64
+	// Field is "login" or "email"
+	Field string
+	Value string
+	// Owner is uuid of the user holding the normalized identifier
+	Owner uint64
 }
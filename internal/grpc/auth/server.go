@@ -2,31 +2,94 @@ package grpcauth
 
 import (
 	"Service/internal/domain/models"
+	"Service/internal/grpc/authz"
+	"Service/internal/grpc/clientinfo"
+	"Service/internal/lib/identity"
 	"Service/internal/services/auth"
 	"context"
 	"errors"
 	"fmt"
 	"net/mail"
+	"strings"
+	"time"
 
 	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 type Auth interface {
 	Login(
 		ctx context.Context,
 		login, password string,
+		device models.Device,
 	) (models.TokensPair, error)
 	SignUp(
 		ctx context.Context,
 		login, email, password string,
+		device models.Device,
 	) (models.TokensPair, error)
 	UpdateTokens(
 		ctx context.Context,
 		refreshToken string,
 	) (models.TokensPair, error)
+	Introspect(
+		ctx context.Context,
+		accessToken string,
+	) (models.TokenInfo, error)
+	Logout(
+		ctx context.Context,
+		refreshToken string,
+	) error
+	LogoutAll(
+		ctx context.Context,
+		uuid uint64,
+	) error
+	Sessions(
+		ctx context.Context,
+		uuid uint64,
+	) ([]models.Session, error)
+	RevokeSession(
+		ctx context.Context,
+		uuid uint64,
+		sessionID string,
+	) error
+	ChangePassword(
+		ctx context.Context,
+		accessToken, oldPassword, newPassword string,
+	) error
+	RequestPasswordReset(
+		ctx context.Context,
+		email string,
+	) error
+	ConfirmPasswordReset(
+		ctx context.Context,
+		token, newPassword string,
+	) error
+	VerifyEmail(
+		ctx context.Context,
+		code string,
+	) error
+	ResendVerification(
+		ctx context.Context,
+		email string,
+	) error
+	EnrollTOTP(
+		ctx context.Context,
+		accessToken string,
+	) (string, string, error)
+	ConfirmTOTP(
+		ctx context.Context,
+		accessToken, code string,
+	) ([]string, error)
+	CompleteLogin(
+		ctx context.Context,
+		mfaToken, code string,
+		device models.Device,
+	) (models.TokensPair, error)
 }
 type serverAPI struct {
 	authv1.UnimplementedAuthServer
@@ -37,6 +100,33 @@ func Register(grpcsrv *grpc.Server, auth Auth) {
 	authv1.RegisterAuthServer(grpcsrv, &serverAPI{auth: auth})
 }
 
+// ScopeTokensIntrospect allows backend services to introspect tokens
+const ScopeTokensIntrospect = "tokens:introspect"
+
+// Policy returns access policy of Auth-API. Most RPCs are public as they
+// authenticate users by credentials or tokens in the request, RPCs managing
+// sessions by uuid are allowed only for the owner of the sessions. Introspect
+// is internal, so tokens can not be probed anonymously
+func Policy() map[string]authz.Rule {
+	policy := make(map[string]authz.Rule)
+	for _, method := range authv1.Auth_ServiceDesc.Methods {
+		policy["/"+authv1.Auth_ServiceDesc.ServiceName+"/"+method.MethodName] = authz.Rule{Access: authz.Public}
+	}
+
+	policy[authv1.Auth_Introspect_FullMethodName] = authz.Rule{Access: authz.Internal, Scope: ScopeTokensIntrospect}
+	policy[authv1.Auth_LogoutAll_FullMethodName] = authz.OwnedBy(func(req *authv1.LogoutAllRequest) int64 {
+		return int64(req.GetUuid())
+	})
+	policy[authv1.Auth_Sessions_FullMethodName] = authz.OwnedBy(func(req *authv1.SessionsRequest) int64 {
+		return int64(req.GetUuid())
+	})
+	policy[authv1.Auth_RevokeSession_FullMethodName] = authz.OwnedBy(func(req *authv1.RevokeSessionRequest) int64 {
+		return int64(req.GetUuid())
+	})
+
+	return policy
+}
+
 // Login handlers Login-API request
 func (s *serverAPI) Login(
 	ctx context.Context,
@@ -47,11 +137,25 @@ func (s *serverAPI) Login(
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	token, err := s.auth.Login(ctx, req.GetLogin(), req.GetPassword())
+	token, err := s.auth.Login(ctx, req.GetLogin(), req.GetPassword(), clientinfo.Device(ctx))
 	if err != nil {
+		var mfa *auth.MFARequiredError
+		if errors.As(err, &mfa) {
+			return &authv1.LoginResponse{
+				MfaRequired: true,
+				MfaToken:    mfa.Token,
+			}, nil
+		}
 		if errors.Is(err, auth.ErrInvalidArgument) {
 			return nil, status.Error(codes.InvalidArgument, "invalid arguments")
 		}
+		if errors.Is(err, auth.ErrEmailUnverified) {
+			return nil, status.Error(codes.FailedPrecondition, "email is not verified")
+		}
+		var throttled *auth.ThrottledError
+		if errors.As(err, &throttled) {
+			return nil, throttledStatus(throttled.RetryAfter)
+		}
 
 		return nil, status.Error(codes.Internal, "internal error occurred")
 	}
@@ -73,11 +177,21 @@ func (s *serverAPI) SignUp(
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	token, err := s.auth.SignUp(ctx, req.GetLogin(), req.GetEmail(), req.GetPassword())
+	token, err := s.auth.SignUp(
+		ctx,
+		req.GetLogin(),
+		req.GetEmail(),
+		req.GetPassword(),
+		clientinfo.Device(ctx),
+	)
 	if err != nil {
 		if errors.Is(err, auth.ErrInvalidArgument) {
 			return nil, status.Error(codes.InvalidArgument, "invalid arguments")
 		}
+		var weak *auth.WeakPasswordError
+		if errors.As(err, &weak) {
+			return nil, weakPasswordStatus("password", weak.Violations)
+		}
 
 		return nil, status.Error(codes.Internal, "internal error occurred")
 	}
@@ -100,6 +214,12 @@ func (s *serverAPI) UpdateTokens(
 		if errors.Is(err, auth.ErrNoToken) {
 			return nil, status.Error(codes.Unauthenticated, "token does not exist")
 		}
+		if errors.Is(err, auth.ErrTokenReused) {
+			return nil, status.Error(codes.Unauthenticated, "token is already used")
+		}
+		if errors.Is(err, auth.ErrEmailUnverified) {
+			return nil, status.Error(codes.FailedPrecondition, "email is not verified")
+		}
 
 		return nil, status.Error(codes.Internal, "internal error")
 	}
@@ -110,6 +230,359 @@ func (s *serverAPI) UpdateTokens(
 	}, nil
 }
 
+// Introspect handlers Introspect-API request
+func (s *serverAPI) Introspect(
+	ctx context.Context,
+	req *authv1.IntrospectRequest,
+) (*authv1.IntrospectResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	info, err := s.auth.Introspect(ctx, req.GetToken())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if !info.Active {
+		return &authv1.IntrospectResponse{Active: false}, nil
+	}
+
+	resp := &authv1.IntrospectResponse{
+		Active:   true,
+		Uuid:     int32(info.UUID),
+		Login:    info.Login,
+		Exp:      info.ExpiresAt.Unix(),
+		Scopes:   info.Scopes,
+		Roles:    info.Roles,
+		ClientId: info.ClientID,
+	}
+	if !info.IssuedAt.IsZero() {
+		resp.Iat = info.IssuedAt.Unix()
+	}
+
+	return resp, nil
+}
+
+// Logout handlers Logout-API request
+func (s *serverAPI) Logout(
+	ctx context.Context,
+	req *authv1.LogoutRequest,
+) (*authv1.LogoutResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	}
+
+	if err := s.auth.Logout(ctx, req.GetRefreshToken()); err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.LogoutResponse{}, nil
+}
+
+// LogoutAll handlers LogoutAll-API request
+func (s *serverAPI) LogoutAll(
+	ctx context.Context,
+	req *authv1.LogoutAllRequest,
+) (*authv1.LogoutAllResponse, error) {
+	if req.GetUuid() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uuid must be positive")
+	}
+
+	if err := s.auth.LogoutAll(ctx, uint64(req.GetUuid())); err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.LogoutAllResponse{}, nil
+}
+
+// Sessions handlers Sessions-API request
+func (s *serverAPI) Sessions(
+	ctx context.Context,
+	req *authv1.SessionsRequest,
+) (*authv1.SessionsResponse, error) {
+	if req.GetUuid() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uuid must be positive")
+	}
+
+	sessions, err := s.auth.Sessions(ctx, uint64(req.GetUuid()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &authv1.SessionsResponse{
+		Sessions: make([]*authv1.Session, len(sessions)),
+	}
+	for i, session := range sessions {
+		resp.Sessions[i] = &authv1.Session{
+			Id:              session.ID,
+			UserAgent:       session.Device.UserAgent,
+			Ip:              session.Device.IP,
+			CreatedAt:       session.CreatedAt.Unix(),
+			LastRefreshedAt: session.LastRefreshedAt.Unix(),
+		}
+	}
+
+	return resp, nil
+}
+
+// RevokeSession handlers RevokeSession-API request
+func (s *serverAPI) RevokeSession(
+	ctx context.Context,
+	req *authv1.RevokeSessionRequest,
+) (*authv1.RevokeSessionResponse, error) {
+	if req.GetUuid() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uuid must be positive")
+	}
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id is required")
+	}
+
+	err := s.auth.RevokeSession(ctx, uint64(req.GetUuid()), req.GetSessionId())
+	if err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			return nil, status.Error(codes.NotFound, "session is not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.RevokeSessionResponse{}, nil
+}
+
+// ChangePassword handlers ChangePassword-API request
+func (s *serverAPI) ChangePassword(
+	ctx context.Context,
+	req *authv1.ChangePasswordRequest,
+) (*authv1.ChangePasswordResponse, error) {
+	if err := validateChangePassword(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := s.auth.ChangePassword(ctx, req.GetAccessToken(), req.GetOldPassword(), req.GetNewPassword())
+	if err != nil {
+		if errors.Is(err, auth.ErrNoToken) {
+			return nil, status.Error(codes.Unauthenticated, "token is invalid")
+		}
+		if errors.Is(err, auth.ErrInvalidArgument) {
+			return nil, status.Error(codes.InvalidArgument, "invalid arguments")
+		}
+		var weak *auth.WeakPasswordError
+		if errors.As(err, &weak) {
+			return nil, weakPasswordStatus("newPassword", weak.Violations)
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.ChangePasswordResponse{}, nil
+}
+
+// RequestPasswordReset handlers RequestPasswordReset-API request
+func (s *serverAPI) RequestPasswordReset(
+	ctx context.Context,
+	req *authv1.RequestPasswordResetRequest,
+) (*authv1.RequestPasswordResetResponse, error) {
+	if _, err := mail.ParseAddress(req.GetEmail()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid email")
+	}
+
+	if err := s.auth.RequestPasswordReset(ctx, req.GetEmail()); err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.RequestPasswordResetResponse{}, nil
+}
+
+// ConfirmPasswordReset handlers ConfirmPasswordReset-API request
+func (s *serverAPI) ConfirmPasswordReset(
+	ctx context.Context,
+	req *authv1.ConfirmPasswordResetRequest,
+) (*authv1.ConfirmPasswordResetResponse, error) {
+	if err := validateConfirmPasswordReset(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.auth.ConfirmPasswordReset(ctx, req.GetToken(), req.GetNewPassword()); err != nil {
+		if errors.Is(err, auth.ErrNoToken) {
+			return nil, status.Error(codes.InvalidArgument, "reset token is invalid or expired")
+		}
+		var weak *auth.WeakPasswordError
+		if errors.As(err, &weak) {
+			return nil, weakPasswordStatus("newPassword", weak.Violations)
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.ConfirmPasswordResetResponse{}, nil
+}
+
+// VerifyEmail handlers VerifyEmail-API request
+func (s *serverAPI) VerifyEmail(
+	ctx context.Context,
+	req *authv1.VerifyEmailRequest,
+) (*authv1.VerifyEmailResponse, error) {
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	if err := s.auth.VerifyEmail(ctx, req.GetCode()); err != nil {
+		if errors.Is(err, auth.ErrNoToken) {
+			return nil, status.Error(codes.InvalidArgument, "code is invalid or expired")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.VerifyEmailResponse{}, nil
+}
+
+// ResendVerification handlers ResendVerification-API request
+func (s *serverAPI) ResendVerification(
+	ctx context.Context,
+	req *authv1.ResendVerificationRequest,
+) (*authv1.ResendVerificationResponse, error) {
+	if _, err := mail.ParseAddress(req.GetEmail()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid email")
+	}
+
+	if err := s.auth.ResendVerification(ctx, req.GetEmail()); err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.ResendVerificationResponse{}, nil
+}
+
+// EnrollTOTP handlers EnrollTOTP-API request
+func (s *serverAPI) EnrollTOTP(
+	ctx context.Context,
+	req *authv1.EnrollTOTPRequest,
+) (*authv1.EnrollTOTPResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	secret, uri, err := s.auth.EnrollTOTP(ctx, req.GetAccessToken())
+	if err != nil {
+		if errors.Is(err, auth.ErrNoToken) {
+			return nil, status.Error(codes.Unauthenticated, "token is invalid")
+		}
+		if errors.Is(err, auth.ErrMFAEnabled) {
+			return nil, status.Error(codes.AlreadyExists, "second factor is already enabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.EnrollTOTPResponse{
+		Secret: secret,
+		Uri:    uri,
+	}, nil
+}
+
+// ConfirmTOTP handlers ConfirmTOTP-API request
+func (s *serverAPI) ConfirmTOTP(
+	ctx context.Context,
+	req *authv1.ConfirmTOTPRequest,
+) (*authv1.ConfirmTOTPResponse, error) {
+	if req.GetAccessToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	recoveryCodes, err := s.auth.ConfirmTOTP(ctx, req.GetAccessToken(), req.GetCode())
+	if err != nil {
+		if errors.Is(err, auth.ErrNoToken) {
+			return nil, status.Error(codes.Unauthenticated, "token is invalid")
+		}
+		if errors.Is(err, auth.ErrMFAEnabled) {
+			return nil, status.Error(codes.AlreadyExists, "second factor is already enabled")
+		}
+		if errors.Is(err, auth.ErrMFANotEnrolled) {
+			return nil, status.Error(codes.FailedPrecondition, "second factor is not enrolled")
+		}
+		if errors.Is(err, auth.ErrInvalidCode) {
+			return nil, status.Error(codes.InvalidArgument, "invalid code")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// CompleteLogin handlers CompleteLogin-API request
+func (s *serverAPI) CompleteLogin(
+	ctx context.Context,
+	req *authv1.CompleteLoginRequest,
+) (*authv1.CompleteLoginResponse, error) {
+	if req.GetMfaToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "mfa token is required")
+	}
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	tokens, err := s.auth.CompleteLogin(ctx, req.GetMfaToken(), req.GetCode(), clientinfo.Device(ctx))
+	if err != nil {
+		if errors.Is(err, auth.ErrNoToken) {
+			return nil, status.Error(codes.Unauthenticated, "mfa token is invalid or expired")
+		}
+		if errors.Is(err, auth.ErrInvalidCode) {
+			return nil, status.Error(codes.InvalidArgument, "invalid code")
+		}
+		var throttled *auth.ThrottledError
+		if errors.As(err, &throttled) {
+			return nil, throttledStatus(throttled.RetryAfter)
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &authv1.CompleteLoginResponse{
+		AccessToken:  tokens.AccessToken.Val,
+		RefreshToken: tokens.RefreshToken.Val,
+	}, nil
+}
+
+// throttledStatus returns ResourceExhausted status telling the client when to
+// retry
+func throttledStatus(retryAfter time.Duration) error {
+	st, err := status.New(codes.ResourceExhausted, "too many requests").WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "too many requests")
+	}
+
+	return st.Err()
+}
+
+// weakPasswordStatus returns InvalidArgument status with BadRequest details
+// listing every broken rule of password policy for the field
+func weakPasswordStatus(field string, violations []models.PasswordViolation) error {
+	details := &errdetails.BadRequest{
+		FieldViolations: make([]*errdetails.BadRequest_FieldViolation, len(violations)),
+	}
+	for i, v := range violations {
+		details.FieldViolations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Reason:      v.Rule,
+			Description: v.Message,
+		}
+	}
+
+	st, err := status.New(codes.InvalidArgument, "password is too weak").WithDetails(details)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "password is too weak")
+	}
+
+	return st.Err()
+}
+
 // validateLogin validates user's request to log in
 func validateLogin(req *authv1.LoginRequest) error {
 
@@ -117,8 +590,8 @@ func validateLogin(req *authv1.LoginRequest) error {
 		return fmt.Errorf("login is required")
 	}
 
-	if len(req.GetPassword()) < 8 {
-		return fmt.Errorf("password must be at least 8 chars")
+	if req.GetPassword() == "" {
+		return fmt.Errorf("password is required")
 	}
 
 	return nil
@@ -127,16 +600,52 @@ func validateLogin(req *authv1.LoginRequest) error {
 // validateSignUp validates user's request to sign up
 func validateSignUp(req *authv1.SignUpRequest) error {
 
-	if req.GetLogin() == "" {
+	if strings.TrimSpace(req.GetLogin()) == "" {
 		return fmt.Errorf("login is required")
 	}
 
-	if _, err := mail.ParseAddress(req.GetEmail()); err != nil {
+	if identity.LooksLikeEmail(req.GetLogin()) {
+		return fmt.Errorf("login must not contain '@'")
+	}
+
+	if _, err := mail.ParseAddress(strings.TrimSpace(req.GetEmail())); err != nil {
 		return fmt.Errorf("invalid email")
 	}
 
-	if len(req.GetPassword()) < 8 {
-		return fmt.Errorf("password must be at least 8 chars")
+	if req.GetPassword() == "" {
+		return fmt.Errorf("password is required")
+	}
+
+	return nil
+}
+
+// validateChangePassword validates user's request to change password
+func validateChangePassword(req *authv1.ChangePasswordRequest) error {
+
+	if req.GetAccessToken() == "" {
+		return fmt.Errorf("access token is required")
+	}
+
+	if req.GetOldPassword() == "" {
+		return fmt.Errorf("old password is required")
+	}
+
+	if req.GetNewPassword() == "" {
+		return fmt.Errorf("new password is required")
+	}
+
+	return nil
+}
+
+// validateConfirmPasswordReset validates user's request to reset password
+func validateConfirmPasswordReset(req *authv1.ConfirmPasswordResetRequest) error {
+
+	if req.GetToken() == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	if req.GetNewPassword() == "" {
+		return fmt.Errorf("new password is required")
 	}
 
 	return nil
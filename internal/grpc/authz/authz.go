@@ -0,0 +1,185 @@
+package authz
+
+import (
+	"Service/internal/grpc/clientinfo"
+	"Service/internal/lib/jwt"
+	"Service/internal/lib/logger/sl"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errRevoked = errors.New("token is revoked")
+
+// Access is the level of access required to call an RPC
+type Access int
+
+const (
+	// Authenticated RPCs require valid access token. It is the level of RPCs
+	// missing in the policy
+	Authenticated Access = iota
+	// Public RPCs are available without access token
+	Public
+	// Owner RPCs require the request to act on behalf of the caller
+	Owner
+	// Internal RPCs are available to clients acting on their own behalf,
+	// e.g. backend services. The client must be granted the scope of the rule
+	Internal
+)
+
+// Rule is access policy of an RPC. Owner returns uuid of the user the
+// request acts on behalf of, it is required for Owner access. Scope is
+// required for Internal access
+type Rule struct {
+	Access Access
+	Owner  func(req any) (int64, bool)
+	Scope  string
+}
+
+// OwnedBy returns Owner rule of RPCs with request of type T. The function
+// returns uuid of the user the request acts on behalf of
+func OwnedBy[T any](owner func(req T) int64) Rule {
+	return Rule{
+		Access: Owner,
+		Owner: func(req any) (int64, bool) {
+			r, ok := req.(T)
+			if !ok {
+				return 0, false
+			}
+
+			return owner(r), true
+		},
+	}
+}
+
+type TokenParser interface {
+	ParseToken(token string) (jwt.Claims, error)
+}
+
+type RevocationList interface {
+	IsRevoked(jti string) bool
+}
+
+// Authorizer authenticates callers with bearer access tokens and checks
+// access policy of RPCs
+type Authorizer struct {
+	log     *slog.Logger
+	tokens  TokenParser
+	revoked RevocationList
+	policy  map[string]Rule
+}
+
+// New creates authorizer. Policy is keyed by full method name, e.g.
+// "/follow.Follow/Follow"
+func New(
+	log *slog.Logger,
+	tokens TokenParser,
+	revoked RevocationList,
+	policy map[string]Rule,
+) *Authorizer {
+	return &Authorizer{
+		log:     log,
+		tokens:  tokens,
+		revoked: revoked,
+		policy:  policy,
+	}
+}
+
+// UnaryServerInterceptor puts principal of the bearer token into the context
+// of the request and rejects requests not allowed by the policy with
+// Unauthenticated or PermissionDenied status. Invalid tokens sent to public
+// RPCs are ignored
+func (a *Authorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		log := a.log.With(slog.String("method", info.FullMethod))
+		rule := a.policy[info.FullMethod]
+
+		principal, authenticated := Principal{}, false
+		if token := clientinfo.BearerToken(ctx); token != "" {
+			p, err := a.authenticate(token)
+			switch {
+			case err == nil:
+				principal, authenticated = p, true
+				ctx = WithPrincipal(ctx, p)
+			case rule.Access != Public:
+				log.Warn("invalid access token", sl.Err(err))
+				return nil, status.Error(codes.Unauthenticated, "access token is invalid")
+			}
+		}
+
+		if rule.Access == Public {
+			return handler(ctx, req)
+		}
+
+		if !authenticated {
+			return nil, status.Error(codes.Unauthenticated, "access token is required")
+		}
+
+		if rule.Access == Internal {
+			if !principal.Client || !slices.Contains(principal.Scopes, rule.Scope) {
+				log.Warn(
+					"internal request without scope",
+					slog.String("client", principal.ClientID),
+					slog.String("scope", rule.Scope),
+				)
+				return nil, status.Error(codes.PermissionDenied, "request is not allowed for the caller")
+			}
+
+			return handler(ctx, req)
+		}
+
+		// tokens of clients, acting either on their own behalf or on behalf of
+		// users with the consented scopes, are not first-party logins
+		if principal.ClientID != "" {
+			log.Warn("client request to user rpc", slog.String("client", principal.ClientID))
+			return nil, status.Error(codes.PermissionDenied, "request is not allowed for the caller")
+		}
+
+		if rule.Access == Owner {
+			owner, ok := rule.Owner(req)
+			if !ok || owner < 0 || uint64(owner) != principal.UUID {
+				log.Warn(
+					"request on behalf of another user",
+					slog.Uint64("uuid", principal.UUID),
+					slog.Int64("owner", owner),
+				)
+				return nil, status.Error(codes.PermissionDenied, "request is not allowed for the caller")
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// authenticate verifies the access token and returns its principal
+func (a *Authorizer) authenticate(token string) (Principal, error) {
+	claims, err := a.tokens.ParseToken(token)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	if a.revoked.IsRevoked(claims.ID) {
+		return Principal{}, errRevoked
+	}
+
+	return Principal{
+		UUID:      claims.UUID,
+		Login:     claims.Login,
+		SessionID: claims.SessionID,
+		ClientID:  claims.ClientID,
+		Scopes:    strings.Fields(claims.Scope),
+		Roles:     claims.Roles,
+		Client:    claims.IsClient(),
+	}, nil
+}
@@ -0,0 +1,29 @@
+package authz
+
+import "context"
+
+// Principal is the authenticated caller of the request. It is either a user
+// or a client acting on its own behalf
+type Principal struct {
+	UUID      uint64
+	Login     string
+	SessionID string
+	ClientID  string
+	Scopes    []string
+	Roles     []string
+	Client    bool
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns copy of the context carrying the principal
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns principal of the request if the caller is
+// authenticated
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
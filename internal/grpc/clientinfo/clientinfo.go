@@ -0,0 +1,59 @@
+package clientinfo
+
+import (
+	"Service/internal/domain/models"
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Device returns information about the client device which sent the request
+func Device(ctx context.Context) models.Device {
+	device := models.Device{
+		IP: IP(ctx),
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			device.UserAgent = ua[0]
+		}
+	}
+
+	return device
+}
+
+// IP returns address of the peer without port
+func IP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// BearerToken returns token from "authorization" metadata of the request. It
+// returns empty string if there is no bearer token
+func BearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, value := range md.Get("authorization") {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "bearer") && token != "" {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	return ""
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"Service/internal/domain/models"
+	"Service/internal/grpc/authz"
 	"Service/internal/lib/mappers"
 	"Service/internal/services/follow"
 
@@ -42,12 +43,27 @@ func Register(srvr *grpc.Server, followProvider FollowProvider) {
 	followv1.RegisterFollowServer(srvr, &serverAPI{followProvider: followProvider})
 }
 
+// Policy returns access policy of Follow-API. Users follow and unfollow only
+// on their own behalf, lists of followers are public
+func Policy() map[string]authz.Rule {
+	return map[string]authz.Rule{
+		followv1.Follow_Follow_FullMethodName: authz.OwnedBy(func(req *followv1.FollowRequest) int64 {
+			return int64(req.GetSrc())
+		}),
+		followv1.Follow_Unfollow_FullMethodName: authz.OwnedBy(func(req *followv1.UnfollowRequest) int64 {
+			return int64(req.GetSrc())
+		}),
+		followv1.Follow_Followers_FullMethodName: {Access: authz.Public},
+		followv1.Follow_Followees_FullMethodName: {Access: authz.Public},
+	}
+}
+
 func (s *serverAPI) Follow(
 	ctx context.Context,
 	req *followv1.FollowRequest,
 ) (*followv1.FollowResponse, error) {
 	if err := validateIds(int(req.GetSrc()), int(req.GetTarget())); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	err := s.followProvider.Follow(ctx, int(req.GetSrc()), int(req.GetTarget()))
@@ -70,7 +86,7 @@ func (s *serverAPI) Unfollow(
 	req *followv1.UnfollowRequest,
 ) (*followv1.UnfollowResponse, error) {
 	if err := validateIds(int(req.GetSrc()), int(req.GetTarget())); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	err := s.followProvider.Unfollow(ctx, int(req.GetSrc()), int(req.GetTarget()))
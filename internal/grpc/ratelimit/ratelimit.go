@@ -0,0 +1,186 @@
+package ratelimit
+
+import (
+	"Service/internal/grpc/clientinfo"
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// RetryAfterKey is metadata key with number of seconds to wait before retry
+	RetryAfterKey = "retry-after"
+
+	sweepInterval = time.Minute
+)
+
+// Limit is a token bucket refilled with one token every Every and holding at
+// most Burst tokens. Zero Every means no limit
+type Limit struct {
+	Every time.Duration
+	Burst int
+}
+
+// MethodLimits are limits of a single RPC
+type MethodLimits struct {
+	// PerIP limits requests of every peer IP
+	PerIP Limit
+	// PerSubject limits requests of every authenticated subject
+	PerSubject Limit
+}
+
+// SubjectFunc returns authenticated subject of the request, if there is one
+type SubjectFunc func(ctx context.Context) (string, bool)
+
+// Limiter keeps token buckets of peers and subjects per RPC
+type Limiter struct {
+	log       *slog.Logger
+	def       MethodLimits
+	methods   map[string]MethodLimits
+	subject   SubjectFunc
+	mu        sync.Mutex
+	buckets   map[string]*rate.Limiter
+	lastSweep time.Time
+}
+
+// New creates limiter. Methods are keyed by full method name, e.g.
+// "/auth.Auth/SignUp". Methods which are not listed use the default limits
+func New(
+	log *slog.Logger,
+	def MethodLimits,
+	methods map[string]MethodLimits,
+	subject SubjectFunc,
+) *Limiter {
+	return &Limiter{
+		log:       log,
+		def:       def,
+		methods:   methods,
+		subject:   subject,
+		buckets:   make(map[string]*rate.Limiter),
+		lastSweep: time.Now(),
+	}
+}
+
+// PerIPInterceptor rejects requests exceeding per-IP limits of the method. It
+// does not depend on authentication, so it is chained before the authorizer
+// and requests with invalid tokens are limited too
+func (l *Limiter) PerIPInterceptor() grpc.UnaryServerInterceptor {
+	return l.interceptor(func(ctx context.Context, limits MethodLimits) (string, Limit, bool) {
+		ip := clientinfo.IP(ctx)
+		return "ip:" + ip, limits.PerIP, ip != ""
+	})
+}
+
+// PerSubjectInterceptor rejects requests exceeding per-subject limits of the
+// method. Subject is put into the context by the authorizer, so it is chained
+// after the authorizer
+func (l *Limiter) PerSubjectInterceptor() grpc.UnaryServerInterceptor {
+	return l.interceptor(func(ctx context.Context, limits MethodLimits) (string, Limit, bool) {
+		if l.subject == nil {
+			return "", Limit{}, false
+		}
+
+		sub, ok := l.subject(ctx)
+		return "sub:" + sub, limits.PerSubject, ok
+	})
+}
+
+// interceptor rejects requests exceeding limit of the bucket returned by
+// bucket with ResourceExhausted status. Time to wait is sent in "retry-after"
+// metadata and in RetryInfo details
+func (l *Limiter) interceptor(
+	bucket func(ctx context.Context, limits MethodLimits) (string, Limit, bool),
+) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		limits, ok := l.methods[info.FullMethod]
+		if !ok {
+			limits = l.def
+		}
+
+		key, limit, ok := bucket(ctx, limits)
+		if !ok || limit.Every <= 0 {
+			return handler(ctx, req)
+		}
+
+		if wait := l.take(info.FullMethod+"|"+key, limit); wait > 0 {
+			l.log.Warn(
+				"rate limit exceeded",
+				slog.String("method", info.FullMethod),
+				slog.Duration("retry-after", wait),
+			)
+			return nil, exhausted(ctx, wait)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// take takes a token from the bucket. If the bucket is empty, no token is
+// taken and time to wait is returned
+func (l *Limiter) take(key string, limit Limit) time.Duration {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = rate.NewLimiter(rate.Every(limit.Every), max(limit.Burst, 1))
+		l.buckets[key] = b
+	}
+
+	r := b.ReserveN(now, 1)
+	wait := r.DelayFrom(now)
+	if wait > 0 {
+		r.CancelAt(now)
+	}
+
+	return wait
+}
+
+// sweep removes buckets which are refilled up to their burst. Such buckets
+// are the same as new ones, while partially drained buckets are kept however
+// long their refill takes. It must be called under lock
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.TokensAt(now) >= float64(b.Burst()) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastSweep = now
+}
+
+func exhausted(ctx context.Context, wait time.Duration) error {
+	seconds := int(math.Ceil(wait.Seconds()))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterKey, strconv.Itoa(seconds)))
+
+	st, err := status.New(codes.ResourceExhausted, "rate limit exceeded").WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)},
+	)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	return st.Err()
+}
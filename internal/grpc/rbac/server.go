@@ -0,0 +1,117 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+
+	"Service/internal/grpc/authz"
+	"Service/internal/services/rbac"
+
+	rbacv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/rbac"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type RBAC interface {
+	AssignRole(ctx context.Context, actor, uuid uint64, role string) error
+	RevokeRole(ctx context.Context, actor, uuid uint64, role string) error
+	Roles(ctx context.Context, actor, uuid uint64) ([]string, []string, error)
+}
+
+type serverAPI struct {
+	rbac RBAC
+	rbacv1.UnimplementedRBACServer
+}
+
+func Register(srvr *grpc.Server, rbac RBAC) {
+	rbacv1.RegisterRBACServer(srvr, &serverAPI{rbac: rbac})
+}
+
+// Policy returns access policy of RBAC-API. Every RPC requires access token,
+// permissions of the caller are checked by the service
+func Policy() map[string]authz.Rule {
+	return map[string]authz.Rule{
+		rbacv1.RBAC_AssignRole_FullMethodName: {Access: authz.Authenticated},
+		rbacv1.RBAC_RevokeRole_FullMethodName: {Access: authz.Authenticated},
+		rbacv1.RBAC_Roles_FullMethodName:      {Access: authz.Authenticated},
+	}
+}
+
+func (s *serverAPI) AssignRole(
+	ctx context.Context,
+	req *rbacv1.AssignRoleRequest,
+) (*rbacv1.AssignRoleResponse, error) {
+	if err := validateRoleRequest(req.GetUuid(), req.GetRole()); err != nil {
+		return nil, err
+	}
+
+	principal, _ := authz.PrincipalFrom(ctx)
+	err := s.rbac.AssignRole(ctx, principal.UUID, uint64(req.GetUuid()), req.GetRole())
+	if err != nil {
+		return nil, rbacStatus(err)
+	}
+
+	return &rbacv1.AssignRoleResponse{}, nil
+}
+
+func (s *serverAPI) RevokeRole(
+	ctx context.Context,
+	req *rbacv1.RevokeRoleRequest,
+) (*rbacv1.RevokeRoleResponse, error) {
+	if err := validateRoleRequest(req.GetUuid(), req.GetRole()); err != nil {
+		return nil, err
+	}
+
+	principal, _ := authz.PrincipalFrom(ctx)
+	err := s.rbac.RevokeRole(ctx, principal.UUID, uint64(req.GetUuid()), req.GetRole())
+	if err != nil {
+		return nil, rbacStatus(err)
+	}
+
+	return &rbacv1.RevokeRoleResponse{}, nil
+}
+
+func (s *serverAPI) Roles(
+	ctx context.Context,
+	req *rbacv1.RolesRequest,
+) (*rbacv1.RolesResponse, error) {
+	if req.GetUuid() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uuid is required")
+	}
+
+	principal, _ := authz.PrincipalFrom(ctx)
+	roles, permissions, err := s.rbac.Roles(ctx, principal.UUID, uint64(req.GetUuid()))
+	if err != nil {
+		return nil, rbacStatus(err)
+	}
+
+	return &rbacv1.RolesResponse{Roles: roles, Permissions: permissions}, nil
+}
+
+func validateRoleRequest(uuid int32, role string) error {
+	if uuid <= 0 {
+		return status.Error(codes.InvalidArgument, "uuid is required")
+	}
+	if role == "" {
+		return status.Error(codes.InvalidArgument, "role is required")
+	}
+
+	return nil
+}
+
+// rbacStatus maps errors of the service to gRPC statuses
+func rbacStatus(err error) error {
+	switch {
+	case errors.Is(err, rbac.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "not enough permissions")
+	case errors.Is(err, rbac.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user is not found")
+	case errors.Is(err, rbac.ErrRoleNotFound):
+		return status.Error(codes.InvalidArgument, "role is not found")
+	case errors.Is(err, rbac.ErrLastAdmin):
+		return status.Error(codes.FailedPrecondition, "last admin can not be revoked")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
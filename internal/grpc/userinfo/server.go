@@ -2,6 +2,7 @@ package grpcusrinfo
 
 import (
 	"Service/internal/domain/models"
+	"Service/internal/grpc/authz"
 	"Service/internal/lib/mappers"
 	"Service/internal/services/userinfo"
 	"context"
@@ -31,6 +32,21 @@ func Register(grpcsrv *grpc.Server, usrInfo UserInfo) {
 	userinfov1.RegisterUserInfoServer(grpcsrv, &serverAPI{usrInfo: usrInfo})
 }
 
+// ScopeUsersRead allows backend services to check users with internal RPCs
+const ScopeUsersRead = "users:read"
+
+// Policy returns access policy of UserInfo-API. Every RPC requires
+// authentication, emails are shown only to their owners. UsersExist is called
+// by backend services only
+func Policy() map[string]authz.Rule {
+	return map[string]authz.Rule{
+		userinfov1.UserInfo_Users_FullMethodName:        {Access: authz.Authenticated},
+		userinfov1.UserInfo_User_FullMethodName:         {Access: authz.Authenticated},
+		userinfov1.UserInfo_UsersByLogin_FullMethodName: {Access: authz.Authenticated},
+		userinfov1.UserInfo_UsersExist_FullMethodName:   {Access: authz.Internal, Scope: ScopeUsersRead},
+	}
+}
+
 func (s *serverAPI) Users(ctx context.Context, u *userinfov1.UsersRequest) (*userinfov1.UsersResponse, error) {
 	if err := validateUUIDs(u.GetUuids()...); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -42,7 +58,7 @@ func (s *serverAPI) Users(ctx context.Context, u *userinfov1.UsersRequest) (*use
 	}
 
 	return &userinfov1.UsersResponse{
-		Users: mappers.ModelUsersToAPI(users...),
+		Users: mappers.ModelUsersToAPI(hideEmails(ctx, users...)...),
 	}, nil
 }
 
@@ -60,7 +76,7 @@ func (s *serverAPI) UsersByLogin(
 	}
 
 	return &userinfov1.UsersByLoginResponse{
-		Users: mappers.ModelUsersToAPI(users...),
+		Users: mappers.ModelUsersToAPI(hideEmails(ctx, users...)...),
 	}, nil
 }
 func (s *serverAPI) User(ctx context.Context, u *userinfov1.UserRequest) (*userinfov1.UserResponse, error) {
@@ -76,12 +92,14 @@ func (s *serverAPI) User(ctx context.Context, u *userinfov1.UserRequest) (*useri
 
 		return nil, status.Error(codes.Internal, "internal error")
 	}
+	user = hideEmails(ctx, user)[0]
 
 	return &userinfov1.UserResponse{
 		User: &userv1.User{
-			Uuid:  int32(user.UUID),
-			Login: user.Login,
-			Email: user.Email,
+			Uuid:          int32(user.UUID),
+			Login:         user.Login,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerified,
 		},
 	}, nil
 }
@@ -100,6 +118,20 @@ func (s *serverAPI) UsersExist(ctx context.Context, u *userinfov1.UsersExistRequ
 		Exist: exist,
 	}, nil
 }
+
+// hideEmails clears emails of users other than the caller
+func hideEmails(ctx context.Context, users ...models.User) []models.User {
+	principal, ok := authz.PrincipalFrom(ctx)
+	for i := range users {
+		if !ok || users[i].UUID != principal.UUID {
+			users[i].Email = ""
+			users[i].EmailVerified = false
+		}
+	}
+
+	return users
+}
+
 func validateUUIDs(uuids ...int32) error {
 	for _, uuid := range uuids {
 		if uuid < 0 {
@@ -0,0 +1,399 @@
+package oauth
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/services/auth"
+	"Service/internal/services/oauth"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type OAuth interface {
+	CheckAuthorization(req models.AuthorizationRequest) (models.OAuthClient, []string, error)
+	IssueCode(
+		ctx context.Context,
+		req models.AuthorizationRequest,
+		user models.User,
+		device models.Device,
+	) (string, error)
+	ExchangeCode(
+		ctx context.Context,
+		clientID, secret, code, redirectURI, verifier string,
+	) (models.OAuthTokens, error)
+	Refresh(
+		ctx context.Context,
+		clientID, secret, refreshToken string,
+	) (models.OAuthTokens, error)
+	ClientCredentials(
+		ctx context.Context,
+		clientID, secret string,
+		scopes []string,
+	) (models.OAuthTokens, error)
+}
+
+// Authenticator checks credentials of users signing in on the login page
+type Authenticator interface {
+	Authenticate(
+		ctx context.Context,
+		login, password string,
+		device models.Device,
+	) (models.User, error)
+	CompleteAuthentication(
+		ctx context.Context,
+		mfaToken, code string,
+	) (models.User, error)
+}
+
+type handler struct {
+	log   *slog.Logger
+	oauth OAuth
+	auth  Authenticator
+}
+
+// Register registers endpoints of OAuth 2.0 authorization server on the mux
+func Register(mux *http.ServeMux, log *slog.Logger, oauth OAuth, auth Authenticator) {
+	h := &handler{
+		log:   log,
+		oauth: oauth,
+		auth:  auth,
+	}
+
+	mux.HandleFunc("GET /authorize", h.Authorize)
+	mux.HandleFunc("POST /authorize", h.Consent)
+	mux.HandleFunc("POST /token", h.Token)
+}
+
+// Authorize validates the authorization request and shows login page
+func (h *handler) Authorize(w http.ResponseWriter, r *http.Request) {
+	req := authorizationRequest(r.URL.Query())
+
+	client, scopes, err := h.oauth.CheckAuthorization(req)
+	if err != nil {
+		h.authorizationError(w, r, req, err)
+		return
+	}
+
+	h.renderPage(w, http.StatusOK, pageData{
+		Client: clientName(client),
+		Scopes: scopes,
+		Params: authorizationParams(req),
+	})
+}
+
+// Consent authenticates the user with the submitted credentials and
+// redirects back to the client with authorization code
+func (h *handler) Consent(w http.ResponseWriter, r *http.Request) {
+	const op = "http.oauth.Consent"
+	log := h.log.With(slog.String("op", op))
+
+	if err := r.ParseForm(); err != nil {
+		h.renderPage(w, http.StatusBadRequest, pageData{Fatal: "malformed request"})
+		return
+	}
+	req := authorizationRequest(r.PostForm)
+
+	client, scopes, err := h.oauth.CheckAuthorization(req)
+	if err != nil {
+		h.authorizationError(w, r, req, err)
+		return
+	}
+
+	if r.PostForm.Get("action") != "allow" {
+		redirectError(w, r, req, &oauth.Error{Code: oauth.CodeAccessDenied, Description: "access is denied by the user"})
+		return
+	}
+
+	data := pageData{
+		Client: clientName(client),
+		Scopes: scopes,
+		Params: authorizationParams(req),
+	}
+	device := deviceOf(r)
+
+	var user models.User
+	if mfaToken := r.PostForm.Get("mfa_token"); mfaToken != "" {
+		user, err = h.auth.CompleteAuthentication(r.Context(), mfaToken, r.PostForm.Get("code"))
+	} else {
+		user, err = h.auth.Authenticate(r.Context(), r.PostForm.Get("login"), r.PostForm.Get("password"), device)
+	}
+	if err != nil {
+		var (
+			mfa       *auth.MFARequiredError
+			throttled *auth.ThrottledError
+		)
+		switch {
+		case errors.As(err, &mfa):
+			data.MFAToken = mfa.Token
+		case errors.As(err, &throttled):
+			data.Message = "Too many failed attempts, try again later"
+		case errors.Is(err, auth.ErrInvalidArgument):
+			data.Message = "Invalid login or password"
+		case errors.Is(err, auth.ErrInvalidCode):
+			data.MFAToken = r.PostForm.Get("mfa_token")
+			data.Message = "Invalid authentication code"
+		case errors.Is(err, auth.ErrNoToken):
+			data.Message = "Sign in has expired, try again"
+		case errors.Is(err, auth.ErrEmailUnverified):
+			data.Message = "Email is not verified"
+		default:
+			log.Error("failed to authenticate user", sl.Err(err))
+			redirectError(w, r, req, &oauth.Error{Code: oauth.CodeServerError, Description: "internal error"})
+			return
+		}
+
+		h.renderPage(w, http.StatusOK, data)
+		return
+	}
+
+	code, err := h.oauth.IssueCode(r.Context(), req, user, device)
+	if err != nil {
+		h.authorizationError(w, r, req, err)
+		return
+	}
+
+	redirect(w, r, req, url.Values{"code": {code}})
+}
+
+// Token issues tokens for authorization code, refresh token or credentials
+// of the client
+func (h *handler) Token(w http.ResponseWriter, r *http.Request) {
+	const op = "http.oauth.Token"
+	log := h.log.With(slog.String("op", op))
+
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "malformed request"})
+		return
+	}
+	form := r.PostForm
+
+	clientID, secret, authErr := clientCredentials(r)
+	if authErr != nil {
+		writeTokenError(w, authErr)
+		return
+	}
+
+	var (
+		tokens models.OAuthTokens
+		err    error
+	)
+	switch form.Get("grant_type") {
+	case oauth.GrantAuthorizationCode:
+		tokens, err = h.oauth.ExchangeCode(
+			r.Context(),
+			clientID,
+			secret,
+			form.Get("code"),
+			form.Get("redirect_uri"),
+			form.Get("code_verifier"),
+		)
+	case oauth.GrantRefreshToken:
+		tokens, err = h.oauth.Refresh(r.Context(), clientID, secret, form.Get("refresh_token"))
+	case oauth.GrantClientCredentials:
+		tokens, err = h.oauth.ClientCredentials(r.Context(), clientID, secret, strings.Fields(form.Get("scope")))
+	default:
+		err = &oauth.Error{Code: oauth.CodeUnsupportedGrantType, Description: "grant type is not supported"}
+	}
+	if err != nil {
+		var oauthErr *oauth.Error
+		if !errors.As(err, &oauthErr) {
+			log.Error("failed to issue tokens", sl.Err(err))
+			oauthErr = &oauth.Error{Code: oauth.CodeServerError, Description: "internal error"}
+		}
+
+		if oauthErr.Code == oauth.CodeInvalidClient {
+			w.Header().Set("WWW-Authenticate", `Basic realm="token"`)
+		}
+		writeTokenError(w, oauthErr)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        strings.Join(tokens.Scopes, " "),
+		IDToken:      tokens.IDToken,
+	})
+}
+
+// clientCredentials returns id and secret of the client sent with HTTP Basic
+// authentication or in the form (RFC 6749 2.3.1). Using both is an error
+func clientCredentials(r *http.Request) (string, string, *oauth.Error) {
+	id, secret, basic := r.BasicAuth()
+	if !basic {
+		id = r.PostForm.Get("client_id")
+		if id == "" {
+			return "", "", &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "client_id is required"}
+		}
+
+		return id, r.PostForm.Get("client_secret"), nil
+	}
+
+	if r.PostForm.Has("client_secret") {
+		return "", "", &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "multiple client authentication methods"}
+	}
+
+	// credentials are form-encoded before they are put into the header
+	id, errID := url.QueryUnescape(id)
+	secret, errSecret := url.QueryUnescape(secret)
+	if errID != nil || errSecret != nil {
+		return "", "", &oauth.Error{Code: oauth.CodeInvalidClient, Description: "malformed client credentials"}
+	}
+
+	if formID := r.PostForm.Get("client_id"); formID != "" && formID != id {
+		return "", "", &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "client_id mismatched"}
+	}
+
+	return id, secret, nil
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+type errorResponse struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// authorizationError reports invalid authorization request. Requests with
+// unknown client or redirect URI are not redirected (RFC 6749 4.1.2.1)
+func (h *handler) authorizationError(
+	w http.ResponseWriter,
+	r *http.Request,
+	req models.AuthorizationRequest,
+	err error,
+) {
+	const op = "http.oauth.authorizationError"
+
+	var oauthErr *oauth.Error
+	switch {
+	case errors.Is(err, oauth.ErrInvalidClient), errors.Is(err, oauth.ErrInvalidRedirectURI):
+		h.renderPage(w, http.StatusBadRequest, pageData{Fatal: err.Error()})
+	case errors.As(err, &oauthErr):
+		redirectError(w, r, req, oauthErr)
+	default:
+		h.log.Error("failed to authorize client", slog.String("op", op), sl.Err(err))
+		redirectError(w, r, req, &oauth.Error{Code: oauth.CodeServerError, Description: "internal error"})
+	}
+}
+
+func (h *handler) renderPage(w http.ResponseWriter, status int, data pageData) {
+	header := w.Header()
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Set("Cache-Control", "no-store")
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("Content-Security-Policy", "default-src 'none'; form-action 'self'; frame-ancestors 'none'")
+	w.WriteHeader(status)
+
+	if err := page.Execute(w, data); err != nil {
+		h.log.Error("failed to render page", sl.Err(err))
+	}
+}
+
+func redirectError(w http.ResponseWriter, r *http.Request, req models.AuthorizationRequest, err *oauth.Error) {
+	redirect(w, r, req, url.Values{
+		"error":             {err.Code},
+		"error_description": {err.Description},
+	})
+}
+
+// redirect redirects to the validated redirect URI of the request with the
+// params and the state
+func redirect(w http.ResponseWriter, r *http.Request, req models.AuthorizationRequest, params url.Values) {
+	target, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect uri", http.StatusBadRequest)
+		return
+	}
+
+	query := target.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	target.RawQuery = query.Encode()
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func writeTokenError(w http.ResponseWriter, err *oauth.Error) {
+	status := http.StatusBadRequest
+	if err.Code == oauth.CodeInvalidClient {
+		status = http.StatusUnauthorized
+	}
+	if err.Code == oauth.CodeServerError {
+		status = http.StatusInternalServerError
+	}
+
+	writeJSON(w, status, errorResponse{Error: err.Code, Description: err.Description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func authorizationRequest(values url.Values) models.AuthorizationRequest {
+	return models.AuthorizationRequest{
+		ResponseType:        values.Get("response_type"),
+		ClientID:            values.Get("client_id"),
+		RedirectURI:         values.Get("redirect_uri"),
+		Scopes:              strings.Fields(values.Get("scope")),
+		State:               values.Get("state"),
+		CodeChallenge:       values.Get("code_challenge"),
+		CodeChallengeMethod: values.Get("code_challenge_method"),
+		Nonce:               values.Get("nonce"),
+	}
+}
+
+// authorizationParams returns parameters of the request posted back by the
+// login page
+func authorizationParams(req models.AuthorizationRequest) map[string]string {
+	return map[string]string{
+		"response_type":         req.ResponseType,
+		"client_id":             req.ClientID,
+		"redirect_uri":          req.RedirectURI,
+		"scope":                 strings.Join(req.Scopes, " "),
+		"state":                 req.State,
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+		"nonce":                 req.Nonce,
+	}
+}
+
+func clientName(client models.OAuthClient) string {
+	if client.Name != "" {
+		return client.Name
+	}
+
+	return client.ID
+}
+
+// deviceOf returns the browser of the user signing in
+func deviceOf(r *http.Request) models.Device {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return models.Device{IP: ip, UserAgent: r.UserAgent()}
+}
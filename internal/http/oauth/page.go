@@ -0,0 +1,48 @@
+package oauth
+
+import "html/template"
+
+// page is the login and consent page of /authorize. Parameters of the
+// authorization request are posted back as hidden fields
+var page = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in to {{.Client}}</title>
+</head>
+<body>
+{{if .Fatal}}
+<h1>Authorization failed</h1>
+<p>{{.Fatal}}</p>
+{{else}}
+<h1>Sign in to {{.Client}}</h1>
+{{if .Scopes}}<p>{{.Client}} requests access to:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Message}}<p role="alert">{{.Message}}</p>{{end}}
+<form method="post" action="/authorize">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}
+{{if .MFAToken}}
+<input type="hidden" name="mfa_token" value="{{.MFAToken}}">
+<label>Authentication code <input name="code" autocomplete="one-time-code" required autofocus></label>
+{{else}}
+<label>Login or email <input name="login" autocomplete="username" required autofocus></label>
+<label>Password <input name="password" type="password" autocomplete="current-password" required></label>
+{{end}}
+<button name="action" value="allow">Allow</button>
+<button name="action" value="deny" formnovalidate>Deny</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+type pageData struct {
+	Client   string
+	Scopes   []string
+	Params   map[string]string
+	Message  string
+	MFAToken string
+	Fatal    string
+}
@@ -0,0 +1,120 @@
+package userinfo
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/lib/jwt"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/services/oauth"
+	"Service/internal/services/userinfo"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type TokenParser interface {
+	ParseToken(token string) (jwt.Claims, error)
+}
+
+type RevocationList interface {
+	IsRevoked(jti string) bool
+}
+
+type UserProvider interface {
+	User(ctx context.Context, uuid int) (models.User, error)
+}
+
+type handler struct {
+	log     *slog.Logger
+	tokens  TokenParser
+	revoked RevocationList
+	usrPrv  UserProvider
+}
+
+// Register registers OpenID Connect userinfo endpoint on the mux
+func Register(
+	mux *http.ServeMux,
+	log *slog.Logger,
+	tokens TokenParser,
+	revoked RevocationList,
+	usrPrv UserProvider,
+) {
+	h := &handler{
+		log:     log,
+		tokens:  tokens,
+		revoked: revoked,
+		usrPrv:  usrPrv,
+	}
+
+	mux.HandleFunc("GET /userinfo", h.UserInfo)
+	mux.HandleFunc("POST /userinfo", h.UserInfo)
+}
+
+// UserInfo returns claims about the owner of the bearer access token. The
+// token must be granted openid scope, other claims are released by profile
+// and email scopes as in ID tokens
+func (h *handler) UserInfo(w http.ResponseWriter, r *http.Request) {
+	const op = "userinfo.UserInfo"
+	log := h.log.With(slog.String("op", op))
+
+	token, ok := bearerToken(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="userinfo"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := h.tokens.ParseToken(token)
+	if err != nil {
+		log.Warn("invalid access token", sl.Err(err))
+		writeBearerError(w, http.StatusUnauthorized, "invalid_token")
+		return
+	}
+	if h.revoked.IsRevoked(claims.ID) {
+		log.Warn("access token is revoked", slog.Uint64("uuid", claims.UUID))
+		writeBearerError(w, http.StatusUnauthorized, "invalid_token")
+		return
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if claims.IsClient() || !slices.Contains(scopes, oauth.ScopeOpenID) {
+		log.Warn("token is not granted openid scope", slog.String("client", claims.ClientID))
+		writeBearerError(w, http.StatusForbidden, "insufficient_scope")
+		return
+	}
+
+	user, err := h.usrPrv.User(r.Context(), int(claims.UUID))
+	if err != nil {
+		if errors.Is(err, userinfo.ErrNotFound) {
+			log.Warn("owner of the token is not found", slog.Uint64("uuid", claims.UUID))
+			writeBearerError(w, http.StatusUnauthorized, "invalid_token")
+			return
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(oauth.UserClaims(user, scopes))
+}
+
+// bearerToken returns the token of Authorization header (RFC 6750 2.1)
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+func writeBearerError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="`+code+`"`)
+	w.WriteHeader(status)
+}
@@ -0,0 +1,105 @@
+package wellknown
+
+import (
+	"Service/internal/lib/jwt"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	jwksMaxAge      = 15 * time.Minute
+	discoveryMaxAge = time.Hour
+)
+
+type KeyProvider interface {
+	JWKS() jwt.JWKSet
+	Algorithms() []string
+}
+
+type handler struct {
+	issuer string
+	keys   KeyProvider
+}
+
+// Register registers handlers of well-known documents on the mux
+func Register(mux *http.ServeMux, issuer string, keys KeyProvider) {
+	h := &handler{
+		issuer: strings.TrimSuffix(issuer, "/"),
+		keys:   keys,
+	}
+
+	mux.HandleFunc("GET /.well-known/jwks.json", h.JWKS)
+	mux.HandleFunc("GET /.well-known/openid-configuration", h.Discovery)
+}
+
+// JWKS serves public keys which tokens can be verified with
+func (h *handler) JWKS(w http.ResponseWriter, r *http.Request) {
+	writeCached(w, r, h.keys.JWKS(), jwksMaxAge)
+}
+
+// Discovery serves OpenID provider metadata
+func (h *handler) Discovery(w http.ResponseWriter, r *http.Request) {
+	writeCached(w, r, discovery{
+		Issuer:                           h.issuer,
+		AuthorizationEndpoint:            h.issuer + "/authorize",
+		TokenEndpoint:                    h.issuer + "/token",
+		UserInfoEndpoint:                 h.issuer + "/userinfo",
+		JWKSURI:                          h.issuer + "/.well-known/jwks.json",
+		ScopesSupported:                  []string{"openid", "profile", "email"},
+		ResponseTypesSupported:           []string{"code"},
+		GrantTypesSupported:              []string{"authorization_code", "refresh_token", "client_credentials"},
+		CodeChallengeMethodsSupported:    []string{"S256"},
+		TokenEndpointAuthMethods:         []string{"none", "client_secret_basic", "client_secret_post"},
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: h.keys.Algorithms(),
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce",
+			"preferred_username", "email", "email_verified",
+		},
+	}, discoveryMaxAge)
+}
+
+type discovery struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserInfoEndpoint                 string   `json:"userinfo_endpoint"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	ScopesSupported                  []string `json:"scopes_supported"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	GrantTypesSupported              []string `json:"grant_types_supported"`
+	CodeChallengeMethodsSupported    []string `json:"code_challenge_methods_supported"`
+	TokenEndpointAuthMethods         []string `json:"token_endpoint_auth_methods_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported                  []string `json:"claims_supported"`
+}
+
+// writeCached writes the body as JSON with cache headers. ETag is computed
+// from the body, so clients are able to revalidate cached documents
+func writeCached(w http.ResponseWriter, r *http.Request, body any, maxAge time.Duration) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(raw)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(raw)
+}
@@ -0,0 +1,32 @@
+package events
+
+import (
+	"Service/internal/domain/models"
+	"context"
+	"log/slog"
+)
+
+// Log emits security events to the logger
+type Log struct {
+	log *slog.Logger
+}
+
+// NewLog creates emitter writing events to the logger
+func NewLog(log *slog.Logger) *Log {
+	return &Log{
+		log: log.With(slog.String("component", "security-events")),
+	}
+}
+
+// Emit writes the event to the log
+func (l *Log) Emit(ctx context.Context, event models.SecurityEvent) {
+	l.log.LogAttrs(
+		ctx,
+		slog.LevelWarn,
+		"security event",
+		slog.String("type", event.Type),
+		slog.Uint64("uuid", event.UserID),
+		slog.String("session", event.SessionID),
+		slog.Time("occurred-at", event.Time),
+	)
+}
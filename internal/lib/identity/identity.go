@@ -0,0 +1,24 @@
+package identity
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns the key of login or email used to look up users and to
+// keep them unique. Surrounding spaces are trimmed, the identifier is case
+// folded and normalized to Unicode NFKC, so "Alice", " alice" and "ａｌｉｃｅ"
+// share the same key
+func Normalize(identifier string) string {
+	s := norm.NFKC.String(strings.TrimSpace(identifier))
+	return norm.NFKC.String(cases.Fold().String(s))
+}
+
+// LooksLikeEmail reports whether the login contains '@' after normalization.
+// Users log in with either login or email, so such logins are not allowed.
+// Characters like '＠' are normalized to '@', so the raw login is not enough
+func LooksLikeEmail(login string) bool {
+	return strings.Contains(Normalize(login), "@")
+}
@@ -0,0 +1,90 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is a set of public keys in JSON Web Key Set format
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns public part of the key in JSON Web Key format
+func (k *Key) JWK() JWK {
+	res := JWK{
+		Use: "sig",
+		Alg: k.Method.Alg(),
+		Kid: k.ID,
+	}
+
+	switch pub := k.Public().(type) {
+	case *rsa.PublicKey:
+		res.Kty = "RSA"
+		res.N = encodeSegment(pub.N.Bytes())
+		res.E = encodeSegment(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		point, _ := pub.ECDH()
+		raw := point.Bytes()
+
+		res.Kty = "EC"
+		res.Crv = pub.Curve.Params().Name
+		res.X = encodeSegment(raw[1 : 1+size])
+		res.Y = encodeSegment(raw[1+size:])
+	case ed25519.PublicKey:
+		res.Kty = "OKP"
+		res.Crv = "Ed25519"
+		res.X = encodeSegment(pub)
+	}
+
+	return res
+}
+
+// JWKS returns all verification keys of the ring as JSON Web Key Set
+func (r *KeyRing) JWKS() JWKSet {
+	keys := r.VerificationKeys()
+
+	res := JWKSet{Keys: make([]JWK, len(keys))}
+	for i, k := range keys {
+		res.Keys[i] = k.JWK()
+	}
+
+	return res
+}
+
+// Algorithms returns signing algorithms of all verification keys
+func (r *KeyRing) Algorithms() []string {
+	res := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, k := range r.VerificationKeys() {
+		if _, ok := seen[k.Method.Alg()]; ok {
+			continue
+		}
+
+		seen[k.Method.Alg()] = struct{}{}
+		res = append(res, k.Method.Alg())
+	}
+
+	return res
+}
+
+func encodeSegment(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -1,141 +1,147 @@
 package jwt
 
 import (
-	"Service/internal/domain/models"
-	"errors"
+	"crypto/rand"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 var (
-	ErrExpired = errors.New("token is expired")
+	ErrExpired         = jwt.ErrTokenExpired
+	ErrInvalidAudience = jwt.ErrTokenInvalidAudience
+	ErrInvalidSubject  = jwt.ErrTokenInvalidSubject
 )
 
-var (
-	EncodingMethod = jwt.SigningMethodHS256
-)
-
-func NewAccess(id uint64, login string, secret string, exp time.Duration) (string, error) {
-	token := jwt.New(EncodingMethod)
-
-	claim := token.Claims.(jwt.MapClaims)
-
-	claim["uuid"] = id
-	claim["login"] = login
-	claim["exp"] = time.Now().Add(exp).Unix()
-
-	tokenString, err := token.SignedString([]byte(secret))
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, err
+// Claims is the claim set of access tokens. The same type is used to issue
+// and to parse tokens. Custom "uuid" and "login" claims duplicate standard
+// ones for consumers which still rely on them
+type Claims struct {
+	jwt.RegisteredClaims
+	UUID      uint64   `json:"uuid"`
+	Login     string   `json:"login"`
+	SessionID string   `json:"sid,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
 }
 
-func NewRefresh(secret string, exp time.Duration) (string, error) {
-	token := jwt.New(EncodingMethod)
+// IDClaims is the claim set of OpenID Connect ID tokens. Email claims are
+// filled only if the client is granted email scope
+type IDClaims struct {
+	jwt.RegisteredClaims
+	PreferredUsername string           `json:"preferred_username,omitempty"`
+	Email             string           `json:"email,omitempty"`
+	EmailVerified     *bool            `json:"email_verified,omitempty"`
+	Nonce             string           `json:"nonce,omitempty"`
+	AuthTime          *jwt.NumericDate `json:"auth_time,omitempty"`
+}
 
-	claim := token.Claims.(jwt.MapClaims)
+// IsClient reports whether the token is issued to the client acting on its
+// own behalf rather than on behalf of a user
+func (c Claims) IsClient() bool {
+	return c.UUID == 0 && c.ClientID != "" && c.Subject == c.ClientID
+}
 
-	claim["exp"] = time.Now().Add(exp).Unix()
+// Issuer issues access tokens signed with keys of the ring and validates
+// them against issuer name, allowed audiences and clock skew leeway
+type Issuer struct {
+	Keys      *KeyRing
+	Name      string
+	Audiences []string
+	Leeway    time.Duration
+}
 
-	tokenString, err := token.SignedString([]byte(secret))
-	if err != nil {
-		return "", err
-	}
+// NewAccess creates access token with the claims. Registered claims are filled
+// by the issuer, the resulting claims are returned along with the token
+func (i *Issuer) NewAccess(claims Claims, exp time.Duration) (string, Claims, error) {
+	return i.issue(claims, strconv.FormatUint(claims.UUID, 10), exp)
+}
 
-	return tokenString, err
+// NewClientAccess creates access token of the client acting on its own
+// behalf. Subject of such token is the client id (RFC 9068)
+func (i *Issuer) NewClientAccess(clientID string, scopes []string, exp time.Duration) (string, Claims, error) {
+	return i.issue(Claims{
+		ClientID: clientID,
+		Scope:    strings.Join(scopes, " "),
+	}, clientID, exp)
 }
 
-func NewTokensPair(
-	id uint64,
-	login string,
-	secret string,
-	exp time.Duration,
-	refreshTTL time.Duration,
-) (models.TokensPair, error) {
+// NewID creates ID token for the client with the user claims, subject of the
+// claims has to be set. Audience of ID tokens is the client rather than
+// audiences of the issuer
+func (i *Issuer) NewID(claims IDClaims, clientID string, authTime time.Time, exp time.Duration) (string, error) {
+	const op = "jwt.NewID"
 
-	accessToken, err := NewAccess(id, login, secret, exp)
-	if err != nil {
-		return models.TokensPair{}, err
+	if claims.Subject == "" {
+		return "", fmt.Errorf("%s: subject is empty", op)
 	}
 
-	refreshToken, err := NewRefresh(secret, refreshTTL)
-	if err != nil {
-		return models.TokensPair{}, err
+	now := time.Now()
+	claims.Issuer = i.Name
+	claims.Audience = jwt.ClaimStrings{clientID}
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(exp))
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	if !authTime.IsZero() {
+		claims.AuthTime = jwt.NewNumericDate(authTime)
 	}
 
-	return models.TokensPair{
-		AccessToken: models.Token{
-			Type: models.AccessToken,
-			Val:  accessToken,
-		},
-		RefreshToken: models.Token{
-			Type: models.RefreshToken,
-			Val:  refreshToken,
-		},
-	}, err
+	return i.Keys.sign(claims)
 }
 
-func ValidateToken(token string, secret string) error {
-	var claims TokenToValidate
-	_, err := jwt.ParseWithClaims(
-		token,
-		&claims,
-		func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
-				return []byte(secret), nil
-			}
+func (i *Issuer) issue(claims Claims, subject string, exp time.Duration) (string, Claims, error) {
+	now := time.Now()
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    i.Name,
+		Subject:   subject,
+		Audience:  i.Audiences,
+		ExpiresAt: jwt.NewNumericDate(now.Add(exp)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        rand.Text(),
+	}
 
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-		},
-	)
+	token, err := i.Keys.sign(claims)
 	if err != nil {
-		return err
+		return "", Claims{}, err
 	}
 
-	return nil
+	return token, claims, nil
 }
 
-func ParseToken(token string, secret string) (TokenPayload, error) {
-	var claims TokenPayload
+// ParseToken verifies signature, issuer, audience and validity period of the
+// token and returns its claims
+func (i *Issuer) ParseToken(token string) (Claims, error) {
+	var claims Claims
 	_, err := jwt.ParseWithClaims(
 		token,
 		&claims,
-		func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
-				return []byte(secret), nil
-			}
-
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-		},
+		i.Keys.keyFunc,
+		jwt.WithIssuer(i.Name),
+		jwt.WithLeeway(i.Leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
 	)
 	if err != nil {
-		return TokenPayload{}, err
+		return Claims{}, err
 	}
 
-	return claims, nil
-}
-
-type TokenToValidate struct {
-	Exp int64 `json:"exp"`
-}
-
-func (tc *TokenToValidate) Valid() error {
-	if tc.Exp < time.Now().Unix() {
-		return ErrExpired
+	if len(i.Audiences) > 0 && !slices.ContainsFunc(claims.Audience, i.allowedAudience) {
+		return Claims{}, ErrInvalidAudience
 	}
 
-	return nil
-}
+	if !claims.IsClient() && claims.Subject != strconv.FormatUint(claims.UUID, 10) {
+		return Claims{}, fmt.Errorf("%w: subject does not match uuid", ErrInvalidSubject)
+	}
 
-type TokenPayload struct {
-	Id    int    `json:"id"`
-	Login string `json:"login"`
+	return claims, nil
 }
 
-func (tc *TokenPayload) Valid() error {
-	return nil
+func (i *Issuer) allowedAudience(aud string) bool {
+	return slices.Contains(i.Audiences, aud)
 }
@@ -0,0 +1,273 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoSigningKey   = errors.New("no active signing key")
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrUnsupportedKey = errors.New("unsupported key type")
+)
+
+// Key is a private key used to sign tokens. Tokens signed with the key carry
+// its ID in the "kid" header
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	ActiveFrom time.Time
+	private    crypto.Signer
+}
+
+// NewKey creates signing key from private key. Signing method is chosen
+// according to the type of the key
+func NewKey(id string, private crypto.Signer, activeFrom time.Time) (*Key, error) {
+	method, err := signingMethod(private)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	return &Key{
+		ID:         id,
+		Method:     method,
+		ActiveFrom: activeFrom,
+		private:    private,
+	}, nil
+}
+
+// LoadKey reads PEM encoded private key from the file. PKCS#8, PKCS#1 and SEC 1
+// encodings are supported
+func LoadKey(id, path string, activeFrom time.Time) (*Key, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: no PEM data in %s", id, path)
+	}
+
+	var private any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key %q: %w", id, ErrUnsupportedKey)
+	}
+
+	return NewKey(id, signer, activeFrom)
+}
+
+// GenerateKey writes new PKCS#8 encoded ed25519 private key to the file if
+// there is no file yet. The key is written to a temporary file first and
+// linked to the path, so concurrent callers end up with the same key
+func GenerateKey(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	_, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".key-*")
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = pem.Encode(tmp, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+
+	if err = os.Link(tmp.Name(), path); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("generate key: %w", err)
+	}
+
+	return nil
+}
+
+// Public returns public part of the key
+func (k *Key) Public() crypto.PublicKey {
+	return k.private.Public()
+}
+
+// KeyRing stores signing keys ordered by activation time. The newest key which
+// is already active signs new tokens. Superseded keys are kept for verification
+// during retention period, so tokens issued before rotation stay valid until
+// they expire
+type KeyRing struct {
+	keys      []*Key
+	retention time.Duration
+}
+
+// NewKeyRing creates key ring. Retention should be not less than lifetime of
+// the longest living token
+func NewKeyRing(retention time.Duration, keys ...*Key) (*KeyRing, error) {
+	ids := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		if _, ok := ids[k.ID]; ok {
+			return nil, fmt.Errorf("duplicated key id %q", k.ID)
+		}
+		ids[k.ID] = struct{}{}
+	}
+
+	sorted := make([]*Key, len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+
+	return &KeyRing{
+		keys:      sorted,
+		retention: retention,
+	}, nil
+}
+
+// SigningKey returns the key which must be used to sign new tokens
+func (r *KeyRing) SigningKey() (*Key, error) {
+	now := time.Now()
+
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if !r.keys[i].ActiveFrom.After(now) {
+			return r.keys[i], nil
+		}
+	}
+
+	return nil, ErrNoSigningKey
+}
+
+// VerificationKeys returns all keys which tokens can be verified with. Keys
+// scheduled for future activation are included, so consumers are able to
+// fetch them before rotation
+func (r *KeyRing) VerificationKeys() []*Key {
+	now := time.Now()
+
+	res := make([]*Key, 0, len(r.keys))
+	for i, k := range r.keys {
+		if r.retired(i, now) {
+			continue
+		}
+
+		res = append(res, k)
+	}
+
+	return res
+}
+
+// Key returns verification key by its id
+func (r *KeyRing) Key(id string) (*Key, error) {
+	now := time.Now()
+
+	for i, k := range r.keys {
+		if k.ID == id && !r.retired(i, now) {
+			return k, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// retired reports whether the i-th key was superseded by the next one longer
+// than retention period ago
+func (r *KeyRing) retired(i int, now time.Time) bool {
+	if i == len(r.keys)-1 {
+		return false
+	}
+
+	return r.keys[i+1].ActiveFrom.Add(r.retention).Before(now)
+}
+
+// sign creates token with the claims, signs it with current signing key and
+// sets "kid" header
+func (r *KeyRing) sign(claims jwt.Claims) (string, error) {
+	key, err := r.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.private)
+}
+
+// keyFunc resolves verification key by the "kid" header of the token
+func (r *KeyRing) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, ok := t.Header["kid"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: kid header is missing", ErrUnknownKey)
+	}
+
+	key, err := r.Key(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+
+	return key.Public(), nil
+}
+
+// signingMethod returns signing method matching the private key
+func signingMethod(private crypto.Signer) (jwt.SigningMethod, error) {
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
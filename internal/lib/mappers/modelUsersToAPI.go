@@ -10,9 +10,10 @@ func ModelUsersToAPI(users ...models.User) []*userv1.User {
 
 	for i, user := range users {
 		res[i] = &userv1.User{
-			Uuid:  int32(user.UUID),
-			Login: user.Login,
-			Email: user.Email,
+			Uuid:          int32(user.UUID),
+			Login:         user.Login,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerified,
 		}
 	}
 
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"Service/internal/domain/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Writer delivers notifications by writing them as JSON lines. It is meant for
+// local development and tests, where there is no real mail server
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStdout creates notifier writing to standard output
+func NewStdout() *Writer {
+	return &Writer{w: os.Stdout}
+}
+
+// NewFile creates notifier appending to the file. The file is created if it
+// does not exist
+func NewFile(path string) (*Writer, error) {
+	const op = "notify.NewFile"
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Writer{w: f}, nil
+}
+
+type message struct {
+	Kind    string    `json:"kind"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	Secret  string    `json:"secret,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Notify writes the notification
+func (n *Writer) Notify(ctx context.Context, notification models.Notification) error {
+	const op = "notify.Notify"
+
+	raw, err := json.Marshal(message(notification))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, err = n.w.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package opaque
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+const tokenSize = 32
+
+// NewToken generates random URL-safe token with 256 bits of entropy
+func NewToken() (string, error) {
+	raw := make([]byte, tokenSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Hash returns SHA-256 hash of the token. Only hashes of tokens are stored, so
+// leaked storage does not yield usable tokens
+func Hash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,122 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idID = "argon2id"
+
+// Upper limits of argon2id parameters. Hashes are verified with parameters
+// they carry, so stored hashes must not make verification unbounded
+const (
+	maxArgon2idMemory      = 1 << 20 // 1 GiB
+	maxArgon2idIterations  = 32
+	maxArgon2idParallelism = 64
+	maxArgon2idSaltLength  = 64
+	maxArgon2idKeyLength   = 64
+)
+
+// Argon2idParams are parameters of argon2id. Memory is set in KiB
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+func (p Argon2idParams) validate() error {
+	switch {
+	case p.Memory < 8*uint32(p.Parallelism):
+		return errors.New("argon2id memory must be at least 8 KiB per thread")
+	case p.Memory > maxArgon2idMemory:
+		return fmt.Errorf("argon2id memory must be at most %d KiB", maxArgon2idMemory)
+	case p.Iterations == 0:
+		return errors.New("argon2id iterations must be positive")
+	case p.Iterations > maxArgon2idIterations:
+		return fmt.Errorf("argon2id iterations must be at most %d", maxArgon2idIterations)
+	case p.Parallelism == 0:
+		return errors.New("argon2id parallelism must be positive")
+	case p.Parallelism > maxArgon2idParallelism:
+		return fmt.Errorf("argon2id parallelism must be at most %d", maxArgon2idParallelism)
+	case p.SaltLength < 8:
+		return errors.New("argon2id salt must be at least 8 bytes")
+	case p.SaltLength > maxArgon2idSaltLength:
+		return fmt.Errorf("argon2id salt must be at most %d bytes", maxArgon2idSaltLength)
+	case p.KeyLength < 16:
+		return errors.New("argon2id key must be at least 16 bytes")
+	case p.KeyLength > maxArgon2idKeyLength:
+		return fmt.Errorf("argon2id key must be at most %d bytes", maxArgon2idKeyLength)
+	}
+
+	return nil
+}
+
+// hashArgon2id hashes the password with random salt. The result has the form
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<hash>
+func hashArgon2id(p Argon2idParams, password string) ([]byte, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return fmt.Appendf(
+		nil,
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idID,
+		argon2.Version,
+		p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Hasher) verifyArgon2id(hash []byte, password string) (bool, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 {
+		return false, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, ErrMalformedHash
+	}
+	if version != argon2.Version {
+		return false, ErrUnknownAlgorithm
+	}
+
+	var p Argon2idParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return false, ErrMalformedHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	// parameters out of the limits are rejected before the key is derived
+	if p.validate() != nil {
+		return false, ErrMalformedHash
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, ErrMismatch
+	}
+
+	return p != h.params, nil
+}
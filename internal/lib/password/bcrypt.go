@@ -0,0 +1,21 @@
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// verifyBcrypt verifies hashes made before argon2id became default. Such
+// hashes are always reported for rehash
+func verifyBcrypt(hash []byte, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword(hash, []byte(password))
+	switch {
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, ErrMismatch
+	case err != nil:
+		return false, ErrMalformedHash
+	}
+
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package password
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMismatch         = errors.New("password mismatched")
+	ErrUnknownAlgorithm = errors.New("unknown hash algorithm")
+	ErrMalformedHash    = errors.New("malformed hash")
+)
+
+// Hasher hashes new passwords with argon2id and verifies hashes of every
+// supported algorithm. Hashes are PHC strings, so the algorithm and its
+// parameters are kept along with the hash
+type Hasher struct {
+	params Argon2idParams
+}
+
+// NewHasher creates hasher producing argon2id hashes with the parameters
+func NewHasher(params Argon2idParams) (*Hasher, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Hasher{params: params}, nil
+}
+
+// Hash returns PHC string of the password hash
+func (h *Hasher) Hash(password string) ([]byte, error) {
+	return hashArgon2id(h.params, password)
+}
+
+// Verify checks the password against the hash. It returns ErrMismatch if the
+// password is wrong. Rehash is reported when the hash is made with outdated
+// algorithm or parameters and should be replaced with a new one
+func (h *Hasher) Verify(hash []byte, password string) (rehash bool, err error) {
+	switch algorithm(hash) {
+	case argon2idID:
+		return h.verifyArgon2id(hash, password)
+	case "2a", "2b", "2y":
+		return verifyBcrypt(hash, password)
+	case pbkdf2SHA256ID:
+		return verifyPBKDF2(hash, password)
+	case scryptID:
+		return verifyScrypt(hash, password)
+	default:
+		return false, ErrUnknownAlgorithm
+	}
+}
+
+// algorithm returns id of the algorithm from the hash, e.g. "argon2id" for
+// "$argon2id$v=19$..."
+func algorithm(hash []byte) string {
+	s, ok := strings.CutPrefix(string(hash), "$")
+	if !ok {
+		return ""
+	}
+
+	id, _, _ := strings.Cut(s, "$")
+	return id
+}
+
+// Known reports whether the hash is made with supported algorithm
+func Known(hash []byte) bool {
+	switch algorithm(hash) {
+	case argon2idID, "2a", "2b", "2y", pbkdf2SHA256ID, scryptID:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeBase64 decodes salts and hashes of PHC strings. Padding is optional
+// and "." is accepted instead of "+" as in passlib hashes
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.ReplaceAll(s, ".", "+"), "=")
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,71 @@
+package password
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/subtle"
+	"strconv"
+	"strings"
+)
+
+const pbkdf2SHA256ID = "pbkdf2-sha256"
+
+// Upper limits of PBKDF2 parameters of imported hashes. Every 32 bytes of the
+// key take all the rounds
+const (
+	maxPBKDF2Rounds    = 5_000_000
+	maxPBKDF2KeyLength = 64
+)
+
+// verifyPBKDF2 verifies PBKDF2-SHA256 hashes imported from legacy systems.
+// Both $pbkdf2-sha256$<rounds>$<salt>$<hash> and PHC form with "i=<rounds>"
+// parameter are accepted. Such hashes are always reported for rehash
+func verifyPBKDF2(hash []byte, password string) (bool, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 5 {
+		return false, ErrMalformedHash
+	}
+
+	rounds, err := pbkdf2Rounds(parts[2])
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	salt, err := decodeBase64(parts[3])
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	key, err := decodeBase64(parts[4])
+	if err != nil || len(key) == 0 || len(key) > maxPBKDF2KeyLength {
+		return false, ErrMalformedHash
+	}
+
+	actual, err := pbkdf2.Key(sha256.New, password, salt, rounds, len(key))
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, ErrMismatch
+	}
+
+	return true, nil
+}
+
+// pbkdf2Rounds parses number of rounds from "29000" or "i=29000,l=32"
+func pbkdf2Rounds(params string) (int, error) {
+	for param := range strings.SplitSeq(params, ",") {
+		if v, ok := strings.CutPrefix(param, "i="); ok {
+			params = v
+			break
+		}
+	}
+
+	rounds, err := strconv.Atoi(params)
+	if err != nil {
+		return 0, err
+	}
+	if rounds <= 0 || rounds > maxPBKDF2Rounds {
+		return 0, ErrMalformedHash
+	}
+
+	return rounds, nil
+}
@@ -0,0 +1,58 @@
+package password
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const scryptID = "scrypt"
+
+// Upper limits of scrypt parameters of imported hashes. Memory used to verify
+// a hash is 128*r*N bytes
+const (
+	maxScryptMemory      = 1 << 30 // 1 GiB
+	maxScryptParallelism = 16
+	maxScryptKeyLength   = 64
+)
+
+// verifyScrypt verifies scrypt hashes imported from legacy systems in the form
+// $scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<hash>. Such hashes are always
+// reported for rehash
+func verifyScrypt(hash []byte, password string) (bool, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 5 {
+		return false, ErrMalformedHash
+	}
+
+	var ln, r, p int
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &ln, &r, &p); err != nil {
+		return false, ErrMalformedHash
+	}
+	if ln <= 0 || ln >= 32 || r <= 0 || p <= 0 || p > maxScryptParallelism {
+		return false, ErrMalformedHash
+	}
+	if r > maxScryptMemory>>(ln+7) {
+		return false, ErrMalformedHash
+	}
+	salt, err := decodeBase64(parts[3])
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	key, err := decodeBase64(parts[4])
+	if err != nil || len(key) == 0 || len(key) > maxScryptKeyLength {
+		return false, ErrMalformedHash
+	}
+
+	actual, err := scrypt.Key([]byte(password), salt, 1<<ln, r, p, len(key))
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, ErrMismatch
+	}
+
+	return true, nil
+}
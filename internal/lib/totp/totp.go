@@ -0,0 +1,113 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Period is the time step of codes
+	Period = 30 * time.Second
+	// Digits is the number of digits in codes
+	Digits = 6
+	// Skew is the number of steps before and after the current one whose codes
+	// are accepted, so clocks of the server and the device may drift apart
+	Skew = 1
+
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret generates random secret encoded in base32 without padding
+func NewSecret() (string, error) {
+	raw := make([]byte, secretSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(raw), nil
+}
+
+// URI returns otpauth URI of the secret which authenticator apps accept as
+// QR code
+func URI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}).String()
+}
+
+// Step returns number of the time step the time belongs to
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// Code computes code of the secret at the time
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return code(key, Step(t)), nil
+}
+
+// Validate checks the code against steps around the time. It returns the
+// matched step, so callers are able to reject codes which have been used
+func Validate(secret, c string, t time.Time) (int64, bool) {
+	key, err := decode(secret)
+	if err != nil || len(c) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for step := current - Skew; step <= current+Skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(code(key, step)), []byte(c)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// code computes HOTP value (RFC 4226) of the counter
+func code(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range Digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
+
+func decode(secret string) ([]byte, error) {
+	return encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+}
@@ -0,0 +1,73 @@
+package ttlset
+
+import (
+	"sync"
+	"time"
+)
+
+// Set is a concurrent set of strings, every member of which is removed after
+// its own deadline
+type Set struct {
+	mu            sync.RWMutex
+	members       map[string]time.Time
+	sweepInterval time.Duration
+	lastSweep     time.Time
+}
+
+// New creates empty set. Expired members are swept not more often than once
+// per sweepInterval
+func New(sweepInterval time.Duration) *Set {
+	return &Set{
+		members:       make(map[string]time.Time),
+		sweepInterval: sweepInterval,
+		lastSweep:     time.Now(),
+	}
+}
+
+// Add adds the member to the set until the deadline
+func (s *Set) Add(member string, deadline time.Time) {
+	now := time.Now()
+	if !deadline.After(now) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cur, ok := s.members[member]; !ok || cur.Before(deadline) {
+		s.members[member] = deadline
+	}
+
+	if now.Sub(s.lastSweep) >= s.sweepInterval {
+		s.sweep(now)
+	}
+}
+
+// Contains reports whether the member is in the set and its deadline has not
+// passed yet
+func (s *Set) Contains(member string) bool {
+	s.mu.RLock()
+	deadline, ok := s.members[member]
+	s.mu.RUnlock()
+
+	return ok && deadline.After(time.Now())
+}
+
+// Len returns number of members including expired but not swept yet ones
+func (s *Set) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.members)
+}
+
+// sweep removes expired members. It must be called under write lock
+func (s *Set) sweep(now time.Time) {
+	for member, deadline := range s.members {
+		if !deadline.After(now) {
+			delete(s.members, member)
+		}
+	}
+
+	s.lastSweep = now
+}
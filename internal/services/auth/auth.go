@@ -5,110 +5,300 @@ import (
 	e "Service/internal/lib/errors"
 	"Service/internal/lib/jwt"
 	"Service/internal/lib/logger/sl"
+	"Service/internal/lib/opaque"
 	"Service/internal/storage"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserProvider interface {
 	User(ctx context.Context, key interface{}) (models.User, error)
+	UserByEmail(ctx context.Context, email string) (models.User, error)
+	UserByIdentifier(ctx context.Context, identifier string) (models.User, error)
+	UserRoles(ctx context.Context, uuid uint64) ([]string, error)
 }
 
 type UserSaver interface {
 	Save(ctx context.Context, login, email string, passHash []byte) (uint64, error)
+	UpdatePassword(ctx context.Context, uuid uint64, passHash []byte) error
+	ReplacePassword(ctx context.Context, uuid uint64, oldHash, newHash []byte) (bool, error)
+	ReplacePasswordKeepingSession(
+		ctx context.Context,
+		uuid uint64,
+		oldHash, newHash []byte,
+		keptSession string,
+	) (bool, int64, error)
+	SetEmailVerified(ctx context.Context, uuid uint64) error
 }
 
+// TokenProvider stores refresh tokens and sessions
 type TokenProvider interface {
-	StoreToken(ctx context.Context, refreshToken string, accessToken string) error
-	DeleteToken(ctx context.Context, refreshToken string) error
-	Token(ctx context.Context, token string) (string, error)
+	StoreToken(ctx context.Context, token models.TrackedToken) error
+	Token(ctx context.Context, hash []byte) (models.TrackedToken, error)
+	MarkTokenUsed(ctx context.Context, hash []byte) (bool, error)
+	SessionActive(ctx context.Context, sessionID string) (bool, error)
+	RevokeSession(ctx context.Context, sessionID string) (int64, error)
+	RevokeUserTokens(ctx context.Context, uuid uint64) (int64, error)
+	RevokeUserSession(ctx context.Context, uuid uint64, sessionID string) (int64, error)
+	SaveSession(ctx context.Context, session models.Session) error
+	TouchSession(ctx context.Context, sessionID string, at time.Time) error
+	Sessions(ctx context.Context, uuid uint64) ([]models.Session, error)
+	SessionAccessTokens(ctx context.Context, sessionID string, after time.Time) ([]models.AccessTokenRef, error)
+	UserAccessTokens(
+		ctx context.Context,
+		uuid uint64,
+		exceptSession string,
+		after time.Time,
+	) ([]models.AccessTokenRef, error)
+}
+
+// ResetStore stores password reset tokens
+type ResetStore interface {
+	SaveResetToken(ctx context.Context, token models.ResetToken) error
+	ResetTokenCreatedAt(ctx context.Context, uuid uint64) (time.Time, error)
+	ResetTokenOwner(ctx context.Context, hash []byte, now time.Time) (uint64, error)
+	UseResetToken(ctx context.Context, hash []byte, now time.Time) (uint64, error)
+}
+
+// VerificationStore stores email verification codes
+type VerificationStore interface {
+	SaveVerification(ctx context.Context, verification models.EmailVerification) error
+	VerificationSentAt(ctx context.Context, uuid uint64) (time.Time, error)
+	UseVerification(ctx context.Context, hash []byte, now time.Time) (uint64, error)
+}
+
+// MFAStore stores TOTP secrets, recovery codes and login challenges
+type MFAStore interface {
+	SaveTOTP(ctx context.Context, uuid uint64, secret string) error
+	TOTP(ctx context.Context, uuid uint64) (models.TOTP, error)
+	ConfirmTOTP(ctx context.Context, uuid uint64, step int64, recoveryCodes [][]byte) error
+	AdvanceTOTPStep(ctx context.Context, uuid uint64, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, uuid uint64, hash []byte) (bool, error)
+	SaveMFAChallenge(ctx context.Context, challenge models.MFAChallenge) error
+	MFAChallenge(ctx context.Context, hash []byte) (models.MFAChallenge, error)
+	FailMFAChallenge(ctx context.Context, hash []byte) error
+	DeleteMFAChallenge(ctx context.Context, hash []byte) (bool, error)
+}
+
+// AttemptStore stores counters of failed logins
+type AttemptStore interface {
+	LoginAttempts(ctx context.Context, key string) (models.LoginAttempts, error)
+	AddLoginFailure(ctx context.Context, key string, now, windowStart, expiresAt time.Time) (int, error)
+	LockLogin(ctx context.Context, key string, until, expiresAt time.Time) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+type RevocationList interface {
+	Revoke(ctx context.Context, tokens ...models.AccessTokenRef) error
+	IsRevoked(jti string) bool
+}
+
+type EventEmitter interface {
+	Emit(ctx context.Context, event models.SecurityEvent)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification models.Notification) error
+}
+
+// PasswordPolicy checks new passwords. Identities are user data which must
+// not be part of the password
+type PasswordPolicy interface {
+	Check(password string, identities ...string) []models.PasswordViolation
+}
+
+// PasswordHasher hashes passwords. Verify reports rehash if the hash is made
+// with outdated algorithm or parameters
+type PasswordHasher interface {
+	Hash(password string) ([]byte, error)
+	Verify(hash []byte, password string) (rehash bool, err error)
 }
+
 type Auth struct {
 	log        *slog.Logger
 	usrPrv     UserProvider
 	usrSv      UserSaver
 	tknPrv     TokenProvider
-	secret     string
+	resetSt    ResetStore
+	verifySt   VerificationStore
+	mfaSt      MFAStore
+	attemptSt  AttemptStore
+	events     EventEmitter
+	revoked    RevocationList
+	notifier   Notifier
+	tokens     *jwt.Issuer
+	passwords  PasswordHasher
+	policy     PasswordPolicy
 	tokenTTL   time.Duration
 	refreshTTL time.Duration
+	resetTTL   time.Duration
+
+	resetInterval time.Duration
+	verification  VerificationPolicy
+	mfa           MFAPolicy
+	lockout       LockoutPolicy
+}
+
+// Deps are dependencies and settings of auth service
+type Deps struct {
+	UserProvider      UserProvider
+	UserSaver         UserSaver
+	TokenProvider     TokenProvider
+	ResetStore        ResetStore
+	VerificationStore VerificationStore
+	MFAStore          MFAStore
+	AttemptStore      AttemptStore
+	Events            EventEmitter
+	Revoked           RevocationList
+	Notifier          Notifier
+	Tokens            *jwt.Issuer
+	Passwords         PasswordHasher
+	PasswordPolicy    PasswordPolicy
+	TokenTTL          time.Duration
+	RefreshTTL        time.Duration
+	ResetTTL          time.Duration
+
+	// ResetInterval is minimal interval between reset tokens issued to the
+	// same user
+	ResetInterval time.Duration
+	Verification  VerificationPolicy
+	MFA           MFAPolicy
+	Lockout       LockoutPolicy
 }
 
 // New creates auth service instance
-func New(
-	log *slog.Logger,
-	usrPrv UserProvider,
-	usrSv UserSaver,
-	tknPrv TokenProvider,
-	secret string,
-	tokenTTL time.Duration,
-	refreshTTL time.Duration,
-) *Auth {
+func New(log *slog.Logger, deps Deps) *Auth {
 	return &Auth{
 		log:        log,
-		usrPrv:     usrPrv,
-		usrSv:      usrSv,
-		tknPrv:     tknPrv,
-		secret:     secret,
-		tokenTTL:   tokenTTL,
-		refreshTTL: refreshTTL,
+		usrPrv:     deps.UserProvider,
+		usrSv:      deps.UserSaver,
+		tknPrv:     deps.TokenProvider,
+		resetSt:    deps.ResetStore,
+		verifySt:   deps.VerificationStore,
+		mfaSt:      deps.MFAStore,
+		attemptSt:  deps.AttemptStore,
+		events:     deps.Events,
+		revoked:    deps.Revoked,
+		notifier:   deps.Notifier,
+		tokens:     deps.Tokens,
+		passwords:  deps.Passwords,
+		policy:     deps.PasswordPolicy,
+		tokenTTL:   deps.TokenTTL,
+		refreshTTL: deps.RefreshTTL,
+		resetTTL:   deps.ResetTTL,
+
+		resetInterval: deps.ResetInterval,
+		verification:  deps.Verification,
+		mfa:           deps.MFA,
+		lockout:       deps.Lockout,
 	}
 }
 
-// Login implements login business logic. It returns JWT token with uuid and login, or error
+// Login implements login business logic. It returns JWT token with uuid and login, or error.
+// Login is either login or email of the user, both are matched case-insensitively.
+// Users with second factor get *MFARequiredError to complete login with CompleteLogin
 func (a *Auth) Login(
 	ctx context.Context,
 	login, password string,
+	device models.Device,
 ) (models.TokensPair, error) {
 	const op = "auth.Login"
 	log := a.log.With(slog.String("op", op))
 	log.Info("starting to login user")
 
-	user, err := a.usrPrv.User(ctx, login)
+	user, err := a.Authenticate(ctx, login, password, device)
+	if err != nil {
+		return models.TokensPair{}, e.Fail(op, err)
+	}
+
+	token, err := a.startSession(ctx, user, device, models.Grant{})
+	if err != nil {
+		log.Error("failed to start session", sl.Err(err))
+		return models.TokensPair{}, e.Fail(op, err)
+	}
+
+	log.Info("successfully logged in")
+	return token, nil
+}
+
+// Authenticate checks credentials of the user without starting a session.
+// It applies the same lockout, verification and second factor rules as Login
+func (a *Auth) Authenticate(
+	ctx context.Context,
+	login, password string,
+	device models.Device,
+) (models.User, error) {
+	const op = "auth.Authenticate"
+	log := a.log.With(slog.String("op", op))
+
+	keys := lockoutKeys(login, device)
+	if err := a.checkLockout(ctx, keys); err != nil {
+		log.Warn("login is locked", sl.Err(err))
+		return models.User{}, e.Fail(op, err)
+	}
+
+	user, err := a.usrPrv.UserByIdentifier(ctx, login)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			log.Warn("user is not found", slog.String("login", login))
-			return models.TokensPair{}, fmt.Errorf("%s: %w", op, ErrInvalidArgument)
+			a.registerLoginFailure(ctx, log, keys)
+			return models.User{}, fmt.Errorf("%s: %w", op, ErrInvalidArgument)
 		}
 
 		log.Error("failed to get user", sl.Err(err))
-		return models.TokensPair{}, fmt.Errorf("%s: %w", op, err)
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err = bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
-		log.Warn("password mismatched", sl.Err(err))
-		return models.TokensPair{}, fmt.Errorf("%s: %w", op, ErrInvalidArgument)
+	// failures are counted per account whichever identifier is used
+	if key := loginKey(user.Login); key != keys[0] {
+		keys[0] = key
+		if err = a.checkLockout(ctx, keys[:1]); err != nil {
+			log.Warn("login is locked", sl.Err(err))
+			return models.User{}, e.Fail(op, err)
+		}
 	}
 
-	token, err := jwt.NewTokensPair(user.UUID, user.Login, a.secret, a.tokenTTL, a.refreshTTL)
+	rehash, err := a.passwords.Verify(user.PassHash, password)
 	if err != nil {
-		log.Error("failed to generate JWT", sl.Err(err))
-		return models.TokensPair{}, fmt.Errorf("%s: %w", op, err)
+		log.Warn("password mismatched", sl.Err(err))
+		a.registerLoginFailure(ctx, log, keys)
+		return models.User{}, fmt.Errorf("%s: %w", op, ErrInvalidArgument)
+	}
+
+	if rehash {
+		a.rehashPassword(ctx, log, user, password)
+	}
+
+	if a.verification.Required && !user.EmailVerified {
+		log.Warn("email is not verified", slog.Uint64("uuid", user.UUID))
+		return models.User{}, e.Fail(op, ErrEmailUnverified)
 	}
 
-	err = a.tknPrv.StoreToken(ctx, token.RefreshToken.Val, token.AccessToken.Val)
+	challenge, err := a.mfaChallenge(ctx, user)
 	if err != nil {
-		log.Error(
-			"failed to save token",
-			sl.Err(err),
-			slog.String("refresh-token", token.RefreshToken.Val),
-			slog.String("access-token", token.AccessToken.Val),
-		)
-		return models.TokensPair{}, e.Fail(op, err)
+		log.Error("failed to issue mfa challenge", sl.Err(err))
+		return models.User{}, e.Fail(op, err)
+	}
+	if challenge != nil {
+		// failures are reset once the second factor is passed as well
+		log.Info("second factor is required", slog.Uint64("uuid", user.UUID))
+		return models.User{}, e.Fail(op, challenge)
 	}
+	a.resetLoginFailures(ctx, log, user.Login)
 
-	log.Info("successfully logged in")
-	return token, nil
+	return user, nil
 }
 
-// SignUp implements sign up business logic. It returns JWT token with uuid and login, or error
+// SignUp implements sign up business logic. It returns JWT token with uuid and login, or error.
+// If email verification is required, no session is started and tokens are empty
 func (a *Auth) SignUp(
 	ctx context.Context,
 	login, email, password string,
+	device models.Device,
 ) (models.TokensPair, error) {
 	const op = "grpcapp.SignUp"
 	fail := func(err error) (models.TokensPair, error) {
@@ -117,7 +307,13 @@ func (a *Auth) SignUp(
 	log := a.log.With(slog.String("op", op))
 	log.Info("starting to sign up user")
 
-	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	login, email = strings.TrimSpace(login), strings.TrimSpace(email)
+
+	if err := a.checkPassword(log, password, login, email); err != nil {
+		return fail(err)
+	}
+
+	passHash, err := a.passwords.Hash(password)
 	if err != nil {
 		log.Error("failed to compute hash", sl.Err(err))
 		return fail(err)
@@ -134,20 +330,20 @@ func (a *Auth) SignUp(
 		return fail(err)
 	}
 
-	token, err := jwt.NewTokensPair(uuid, login, a.secret, a.tokenTTL, a.refreshTTL)
-	if err != nil {
-		log.Error("failed to generate JWT token", sl.Err(err))
-		return fail(err)
+	user := models.User{UUID: uuid, Login: login, Email: email}
+
+	if err = a.sendVerification(ctx, user); err != nil {
+		log.Error("failed to send verification code", sl.Err(err))
 	}
 
-	err = a.tknPrv.StoreToken(ctx, token.RefreshToken.Val, token.AccessToken.Val)
+	if a.verification.Required {
+		log.Info("successfully signed up, email is to be verified")
+		return models.TokensPair{}, nil
+	}
+
+	token, err := a.startSession(ctx, user, device, models.Grant{})
 	if err != nil {
-		log.Error(
-			"failed to save tokens",
-			sl.Err(err),
-			slog.String("refresh-token", token.RefreshToken.Val),
-			slog.String("access-token", token.AccessToken.Val),
-		)
+		log.Error("failed to start session", sl.Err(err))
 		return fail(err)
 	}
 
@@ -160,74 +356,325 @@ func (a *Auth) UpdateTokens(
 	refreshToken string,
 ) (models.TokensPair, error) {
 	const op = "auth.UpdateTokens"
-	fail := func(err error) (models.TokensPair, error) {
-		return models.TokensPair{}, e.Fail(op, err)
-	}
 	log := a.log.With(slog.String("op", op))
 	log.Info("starting to update tokens")
 
-	err := jwt.ValidateToken(refreshToken, a.secret)
+	tokens, _, err := a.refresh(ctx, log, refreshToken, "")
+	if err != nil {
+		return models.TokensPair{}, e.Fail(op, err)
+	}
+
+	log.Info("tokens are updated")
+	return tokens, nil
+}
+
+// RefreshClientTokens rotates refresh token issued to the client. Tokens of
+// other clients and of first party logins are not accepted
+func (a *Auth) RefreshClientTokens(
+	ctx context.Context,
+	clientID, refreshToken string,
+) (models.TokensPair, models.Grant, error) {
+	const op = "auth.RefreshClientTokens"
+	log := a.log.With(slog.String("op", op), slog.String("client", clientID))
+	log.Info("starting to refresh client tokens")
+
+	tokens, grant, err := a.refresh(ctx, log, refreshToken, clientID)
 	if err != nil {
-		if errors.Is(err, jwt.ErrExpired) {
-			log.Warn("trying to update expired token")
-			return fail(ErrExpired)
+		return models.TokensPair{}, models.Grant{}, e.Fail(op, err)
+	}
+
+	log.Info("client tokens are refreshed")
+	return tokens, grant, nil
+}
+
+// refresh rotates the refresh token issued to the client and issues new
+// tokens pair with the same grant. Owners of the token have to pass the same
+// verification rule as at login
+func (a *Auth) refresh(
+	ctx context.Context,
+	log *slog.Logger,
+	refreshToken, clientID string,
+) (models.TokensPair, models.Grant, error) {
+	fail := func(err error) (models.TokensPair, models.Grant, error) {
+		return models.TokensPair{}, models.Grant{}, err
+	}
+
+	stored, err := a.tknPrv.Token(ctx, opaque.Hash(refreshToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("trying to update pair with token, which is not tracking")
+			return fail(ErrNoToken)
 		}
 
-		log.Error("failed to validate token", sl.Err(err))
+		log.Error("failed to get refresh token", sl.Err(err))
 		return fail(err)
 	}
 
-	accessToken, err := a.tknPrv.Token(ctx, refreshToken)
+	if stored.Grant.ClientID != clientID {
+		log.Warn("trying to update pair with token of another client", slog.String("owner", stored.Grant.ClientID))
+		return fail(ErrNoToken)
+	}
+
+	if stored.Revoked {
+		log.Warn("trying to update pair with revoked token", slog.String("session", stored.SessionID))
+		return fail(ErrNoToken)
+	}
+
+	if !stored.ExpiresAt.After(time.Now()) {
+		log.Warn("trying to update expired token")
+		return fail(ErrExpired)
+	}
+
+	user, err := a.usrPrv.User(ctx, int(stored.UserID))
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			log.Warn("trying to update pair with token, which is not tracking")
+			log.Warn("owner of the token is not found", slog.Uint64("uuid", stored.UserID))
 			return fail(ErrNoToken)
 		}
 
-		log.Error("failed to get access-token by refresh", sl.Err(err))
+		log.Error("failed to get user", sl.Err(err))
 		return fail(err)
 	}
 
-	payload, err := jwt.ParseToken(accessToken, a.secret)
+	if a.verification.Required && !user.EmailVerified {
+		log.Warn("email is not verified", slog.Uint64("uuid", user.UUID))
+		return fail(ErrEmailUnverified)
+	}
+
+	rotated, err := a.tknPrv.MarkTokenUsed(ctx, stored.Hash)
 	if err != nil {
-		log.Error("failed to parse access token")
+		log.Error("failed to mark refresh token as used", sl.Err(err))
 		return fail(err)
 	}
+	if !rotated {
+		a.revokeReusedSession(ctx, log, stored)
+		return fail(ErrTokenReused)
+	}
 
-	tokens, err := jwt.NewTokensPair(
-		uint64(payload.Id),
-		payload.Login,
-		a.secret,
-		a.tokenTTL,
-		a.refreshTTL,
-	)
+	tokens, err := a.issueTokens(ctx, user, stored.SessionID, stored.Grant)
 	if err != nil {
-		log.Error("failed to generate new tokens pair", sl.Err(err))
+		log.Error("failed to issue tokens", sl.Err(err))
+		return fail(err)
+	}
+
+	if err = a.tknPrv.TouchSession(ctx, stored.SessionID, time.Now()); err != nil {
+		log.Error("failed to update session", sl.Err(err))
 		return fail(err)
 	}
 
-	err = a.tknPrv.StoreToken(ctx, tokens.RefreshToken.Val, tokens.AccessToken.Val)
+	return tokens, stored.Grant, nil
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens are
+// ignored, so logging out is idempotent
+func (a *Auth) Logout(
+	ctx context.Context,
+	refreshToken string,
+) error {
+	const op = "auth.Logout"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to log out")
+
+	stored, err := a.tknPrv.Token(ctx, opaque.Hash(refreshToken))
 	if err != nil {
-		log.Error(
-			"failed to save token",
-			sl.Err(err),
-			slog.String("refresh-token", tokens.RefreshToken.Val),
-			slog.String("access-token", tokens.AccessToken.Val),
-		)
-		return models.TokensPair{}, e.Fail(op, err)
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("trying to log out with token, which is not tracking")
+			return nil
+		}
+
+		log.Error("failed to get refresh token", sl.Err(err))
+		return e.Fail(op, err)
 	}
 
-	err = a.tknPrv.DeleteToken(ctx, refreshToken)
+	if _, err = a.tknPrv.RevokeSession(ctx, stored.SessionID); err != nil {
+		log.Error("failed to revoke session", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	if err = a.revokeSessionAccess(ctx, stored.SessionID); err != nil {
+		log.Error("failed to revoke access tokens", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	log.Info("successfully logged out")
+	return nil
+}
+
+// LogoutAll revokes all sessions of the user
+func (a *Auth) LogoutAll(
+	ctx context.Context,
+	uuid uint64,
+) error {
+	const op = "auth.LogoutAll"
+	log := a.log.With(slog.String("op", op), slog.Uint64("uuid", uuid))
+	log.Info("starting to log out from all sessions")
+
+	n, err := a.tknPrv.RevokeUserTokens(ctx, uuid)
 	if err != nil {
-		log.Error(
-			"failed to delete old token",
-			sl.Err(err),
-			slog.String("refresh-token", tokens.RefreshToken.Val),
-			slog.String("access-token", tokens.AccessToken.Val),
-		)
-		return models.TokensPair{}, e.Fail(op, err)
+		log.Error("failed to revoke user tokens", sl.Err(err))
+		return e.Fail(op, err)
 	}
 
-	log.Info("tokens are updated")
-	return tokens, nil
+	if err = a.revokeUserAccess(ctx, uuid, ""); err != nil {
+		log.Error("failed to revoke access tokens", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	log.Info("successfully logged out from all sessions", slog.Int64("tokens", n))
+	return nil
+}
+
+// Sessions returns active sessions of the user
+func (a *Auth) Sessions(
+	ctx context.Context,
+	uuid uint64,
+) ([]models.Session, error) {
+	const op = "auth.Sessions"
+	log := a.log.With(slog.String("op", op), slog.Uint64("uuid", uuid))
+	log.Info("starting to list sessions")
+
+	sessions, err := a.tknPrv.Sessions(ctx, uuid)
+	if err != nil {
+		log.Error("failed to list sessions", sl.Err(err))
+		return nil, e.Fail(op, err)
+	}
+
+	log.Info("successfully listed sessions")
+	return sessions, nil
+}
+
+// RevokeSession revokes the session of the user
+func (a *Auth) RevokeSession(
+	ctx context.Context,
+	uuid uint64,
+	sessionID string,
+) error {
+	const op = "auth.RevokeSession"
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Uint64("uuid", uuid),
+		slog.String("session", sessionID),
+	)
+	log.Info("starting to revoke session")
+
+	n, err := a.tknPrv.RevokeUserSession(ctx, uuid, sessionID)
+	if err != nil {
+		log.Error("failed to revoke session", sl.Err(err))
+		return e.Fail(op, err)
+	}
+	if n == 0 {
+		log.Warn("session is not found")
+		return e.Fail(op, ErrSessionNotFound)
+	}
+
+	if err = a.revokeSessionAccess(ctx, sessionID); err != nil {
+		log.Error("failed to revoke access tokens", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	log.Info("successfully revoked session")
+	return nil
+}
+
+// ChangePassword changes password of the access token owner. Old password
+// must match the stored hash. All other sessions of the user are revoked, the
+// session of the access token stays alive
+func (a *Auth) ChangePassword(
+	ctx context.Context,
+	accessToken, oldPassword, newPassword string,
+) error {
+	const op = "auth.ChangePassword"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to change password")
+
+	claims, user, err := a.authenticate(ctx, log, accessToken)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	log = log.With(slog.Uint64("uuid", user.UUID))
+
+	if _, err = a.passwords.Verify(user.PassHash, oldPassword); err != nil {
+		log.Warn("password mismatched", sl.Err(err))
+		return e.Fail(op, ErrInvalidArgument)
+	}
+
+	if err = a.checkPassword(log, newPassword, user.Login, user.Email); err != nil {
+		return e.Fail(op, err)
+	}
+
+	passHash, err := a.passwords.Hash(newPassword)
+	if err != nil {
+		log.Error("failed to compute hash", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	// the hash is replaced only if it is still the verified one, so password
+	// changed concurrently is not overwritten
+	replaced, n, err := a.usrSv.ReplacePasswordKeepingSession(ctx, user.UUID, user.PassHash, passHash, claims.SessionID)
+	if err != nil {
+		log.Error("failed to replace password", sl.Err(err))
+		return e.Fail(op, err)
+	}
+	if !replaced {
+		log.Warn("password is changed concurrently")
+		return e.Fail(op, ErrInvalidArgument)
+	}
+
+	if err = a.revokeUserAccess(ctx, user.UUID, claims.SessionID); err != nil {
+		log.Error("failed to revoke access tokens", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	log.Info("successfully changed password", slog.Int64("revoked-tokens", n))
+	return nil
+}
+
+// Introspect checks the access token and returns information about it. Tokens
+// with invalid signature, expired, revoked or belonging to revoked session are
+// reported as inactive
+func (a *Auth) Introspect(
+	ctx context.Context,
+	accessToken string,
+) (models.TokenInfo, error) {
+	const op = "auth.Introspect"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to introspect token")
+
+	claims, err := a.tokens.ParseToken(accessToken)
+	if err != nil {
+		log.Warn("token is invalid", sl.Err(err))
+		return models.TokenInfo{Active: false}, nil
+	}
+
+	if a.revoked.IsRevoked(claims.ID) {
+		log.Warn("token is in revocation list")
+		return models.TokenInfo{Active: false}, nil
+	}
+
+	// tokens of clients acting on their own behalf have no session
+	if !claims.IsClient() {
+		active, err := a.tknPrv.SessionActive(ctx, claims.SessionID)
+		if err != nil {
+			log.Error("failed to check session", sl.Err(err))
+			return models.TokenInfo{}, e.Fail(op, err)
+		}
+		if !active {
+			log.Warn("token is revoked")
+			return models.TokenInfo{Active: false}, nil
+		}
+	}
+
+	info := models.TokenInfo{
+		Active:    true,
+		UUID:      claims.UUID,
+		Login:     claims.Login,
+		ExpiresAt: claims.ExpiresAt.Time,
+		IssuedAt:  claims.IssuedAt.Time,
+		Scopes:    strings.Fields(claims.Scope),
+		Roles:     claims.Roles,
+		ClientID:  claims.ClientID,
+	}
+
+	log.Info("token is introspected")
+	return info, nil
 }
@@ -1,9 +1,38 @@
 package auth
 
-import "errors"
+import (
+	"Service/internal/domain/models"
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrInvalidArgument = errors.New("invalid arguments")
 	ErrExpired         = errors.New("expired token")
 	ErrNoToken         = errors.New("no such token")
+	ErrTokenReused     = errors.New("refresh token is reused")
+	ErrSessionNotFound = errors.New("session is not found")
+	ErrEmailUnverified = errors.New("email is not verified")
+	ErrMFAEnabled      = errors.New("second factor is already enabled")
+	ErrMFANotEnrolled  = errors.New("second factor is not enrolled")
+	ErrInvalidCode     = errors.New("invalid code")
 )
+
+// ThrottledError is returned when the operation is requested too often
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("too many requests, retry after %s", e.RetryAfter)
+}
+
+// WeakPasswordError is returned when new password breaks password policy
+type WeakPasswordError struct {
+	Violations []models.PasswordViolation
+}
+
+func (e *WeakPasswordError) Error() string {
+	return fmt.Sprintf("password breaks %d policy rules", len(e.Violations))
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/lib/identity"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	loginKeyPrefix = "login:"
+	ipKeyPrefix    = "ip:"
+)
+
+// LockoutPolicy configures protection of Login against password guessing.
+// Failures are counted per login and per client IP
+type LockoutPolicy struct {
+	Login LockoutLimit
+	IP    LockoutLimit
+	// Window is the time after the last failure when failures are forgotten
+	Window time.Duration
+}
+
+// LockoutLimit configures lockout of a single key. After Threshold failures
+// the key is locked for BaseDelay, every next failure doubles the delay up to
+// MaxDelay
+type LockoutLimit struct {
+	Threshold int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// lockoutKeys returns keys failures of the login attempt are counted with
+func lockoutKeys(login string, device models.Device) []string {
+	keys := []string{loginKey(login)}
+	if device.IP != "" {
+		keys = append(keys, ipKeyPrefix+device.IP)
+	}
+
+	return keys
+}
+
+// loginKey returns key failures of the login are counted with. Identifiers
+// differing only in case or form share the key
+func loginKey(login string) string {
+	return loginKeyPrefix + identity.Normalize(login)
+}
+
+// checkLockout returns *ThrottledError if any of the keys is locked
+func (a *Auth) checkLockout(ctx context.Context, keys []string) error {
+	now := time.Now()
+
+	var wait time.Duration
+	for _, key := range keys {
+		attempts, err := a.attemptSt.LoginAttempts(ctx, key)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+
+			return fmt.Errorf("failed to get login attempts: %w", err)
+		}
+
+		wait = max(wait, attempts.LockedUntil.Sub(now))
+	}
+
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+// registerLoginFailure counts failed login with every key and locks keys
+// which exceeded their limit
+func (a *Auth) registerLoginFailure(ctx context.Context, log *slog.Logger, keys []string) {
+	now := time.Now()
+
+	for _, key := range keys {
+		failures, err := a.attemptSt.AddLoginFailure(
+			ctx,
+			key,
+			now,
+			now.Add(-a.lockout.Window),
+			now.Add(a.lockout.Window),
+		)
+		if err != nil {
+			log.Error("failed to count login failure", sl.Err(err))
+			continue
+		}
+
+		delay := a.lockout.limit(key).delay(failures)
+		if delay == 0 {
+			continue
+		}
+
+		lockedUntil := now.Add(delay)
+		if err = a.attemptSt.LockLogin(ctx, key, lockedUntil, lockedUntil.Add(a.lockout.Window)); err != nil {
+			log.Error("failed to lock login", sl.Err(err))
+			continue
+		}
+
+		log.Warn(
+			"login is locked",
+			slog.String("key", key),
+			slog.Int("failures", failures),
+			slog.Duration("delay", delay),
+		)
+	}
+}
+
+// resetLoginFailures forgets failures of the login after successful login.
+// Failures of the IP are kept, otherwise an attacker could reset them by
+// logging in to own account
+func (a *Auth) resetLoginFailures(ctx context.Context, log *slog.Logger, login string) {
+	if err := a.attemptSt.ResetLoginAttempts(ctx, loginKey(login)); err != nil {
+		log.Error("failed to reset login attempts", sl.Err(err))
+	}
+}
+
+func (p LockoutPolicy) limit(key string) LockoutLimit {
+	if strings.HasPrefix(key, ipKeyPrefix) {
+		return p.IP
+	}
+
+	return p.Login
+}
+
+// delay returns lockout duration after the number of failures
+func (l LockoutLimit) delay(failures int) time.Duration {
+	if l.Threshold <= 0 || failures < l.Threshold {
+		return 0
+	}
+
+	delay := l.BaseDelay
+	for i := l.Threshold; i < failures && delay < l.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, l.MaxDelay)
+}
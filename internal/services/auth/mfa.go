@@ -0,0 +1,328 @@
+package auth
+
+import (
+	"Service/internal/domain/models"
+	e "Service/internal/lib/errors"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/lib/opaque"
+	"Service/internal/lib/totp"
+	"Service/internal/storage"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodesCount = 10
+	// recoveryCodeSize is number of random bytes of recovery code. Codes are
+	// stored as unsalted hashes, so they have to be long enough to not be
+	// brute-forced
+	recoveryCodeSize = 10
+	// maxMFAAttempts is number of wrong codes after which the challenge is
+	// dropped and login has to be started over
+	maxMFAAttempts = 5
+)
+
+// MFAPolicy configures second factor of authentication
+type MFAPolicy struct {
+	// Issuer is the name authenticator apps show next to the account
+	Issuer string
+	// ChallengeTTL is lifetime of challenges issued after correct password
+	ChallengeTTL time.Duration
+}
+
+// MFARequiredError is returned by Login when the password is correct, but the
+// user has to complete login with second factor code
+type MFARequiredError struct {
+	// Token identifies the challenge to complete
+	Token     string
+	ExpiresAt time.Time
+}
+
+func (e *MFARequiredError) Error() string {
+	return "second factor is required"
+}
+
+// EnrollTOTP generates TOTP secret for the access token owner. The secret
+// starts to be required at login only after ConfirmTOTP. It returns the secret
+// and otpauth URI of it
+func (a *Auth) EnrollTOTP(
+	ctx context.Context,
+	accessToken string,
+) (string, string, error) {
+	const op = "auth.EnrollTOTP"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to enroll totp")
+
+	_, user, err := a.authenticate(ctx, log, accessToken)
+	if err != nil {
+		return "", "", e.Fail(op, err)
+	}
+	log = log.With(slog.Uint64("uuid", user.UUID))
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		log.Error("failed to generate secret", sl.Err(err))
+		return "", "", e.Fail(op, err)
+	}
+
+	if err = a.mfaSt.SaveTOTP(ctx, user.UUID, secret); err != nil {
+		if errors.Is(err, storage.ErrMFAEnabled) {
+			log.Warn("totp is already enabled")
+			return "", "", e.Fail(op, ErrMFAEnabled)
+		}
+
+		log.Error("failed to save secret", sl.Err(err))
+		return "", "", e.Fail(op, err)
+	}
+
+	log.Info("totp is enrolled")
+	return secret, totp.URI(a.mfa.Issuer, user.Login, secret), nil
+}
+
+// ConfirmTOTP enables enrolled TOTP of the access token owner if the code
+// matches the secret. It returns recovery codes which are usable once instead
+// of TOTP codes
+func (a *Auth) ConfirmTOTP(
+	ctx context.Context,
+	accessToken, code string,
+) ([]string, error) {
+	const op = "auth.ConfirmTOTP"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to confirm totp")
+
+	_, user, err := a.authenticate(ctx, log, accessToken)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+	log = log.With(slog.Uint64("uuid", user.UUID))
+
+	factor, err := a.mfaSt.TOTP(ctx, user.UUID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("totp is not enrolled")
+			return nil, e.Fail(op, ErrMFANotEnrolled)
+		}
+
+		log.Error("failed to get totp", sl.Err(err))
+		return nil, e.Fail(op, err)
+	}
+	if factor.Confirmed {
+		log.Warn("totp is already enabled")
+		return nil, e.Fail(op, ErrMFAEnabled)
+	}
+
+	step, ok := totp.Validate(factor.Secret, code, time.Now())
+	if !ok {
+		log.Warn("totp code mismatched")
+		return nil, e.Fail(op, ErrInvalidCode)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		log.Error("failed to generate recovery codes", sl.Err(err))
+		return nil, e.Fail(op, err)
+	}
+
+	if err = a.mfaSt.ConfirmTOTP(ctx, user.UUID, step, hashes); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("totp is already enabled")
+			return nil, e.Fail(op, ErrMFAEnabled)
+		}
+
+		log.Error("failed to confirm totp", sl.Err(err))
+		return nil, e.Fail(op, err)
+	}
+
+	log.Info("totp is enabled")
+	return codes, nil
+}
+
+// CompleteLogin completes two-step login with TOTP or recovery code and
+// starts new session on the device
+func (a *Auth) CompleteLogin(
+	ctx context.Context,
+	mfaToken, code string,
+	device models.Device,
+) (models.TokensPair, error) {
+	const op = "auth.CompleteLogin"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to complete login")
+
+	user, err := a.CompleteAuthentication(ctx, mfaToken, code)
+	if err != nil {
+		return models.TokensPair{}, e.Fail(op, err)
+	}
+
+	tokens, err := a.startSession(ctx, user, device, models.Grant{})
+	if err != nil {
+		log.Error("failed to start session", sl.Err(err))
+		return models.TokensPair{}, e.Fail(op, err)
+	}
+
+	log.Info("successfully logged in")
+	return tokens, nil
+}
+
+// CompleteAuthentication checks TOTP or recovery code of two-step login
+// without starting a session. It returns the authenticated user. Wrong codes
+// are counted as failed logins of the user, so new challenges do not give
+// more attempts
+func (a *Auth) CompleteAuthentication(
+	ctx context.Context,
+	mfaToken, code string,
+) (models.User, error) {
+	const op = "auth.CompleteAuthentication"
+	fail := func(err error) (models.User, error) {
+		return models.User{}, e.Fail(op, err)
+	}
+	log := a.log.With(slog.String("op", op))
+
+	challenge, err := a.mfaSt.MFAChallenge(ctx, opaque.Hash(mfaToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("challenge is not found")
+			return fail(ErrNoToken)
+		}
+
+		log.Error("failed to get challenge", sl.Err(err))
+		return fail(err)
+	}
+	log = log.With(slog.Uint64("uuid", challenge.UserID))
+
+	if !challenge.ExpiresAt.After(time.Now()) || challenge.Attempts >= maxMFAAttempts {
+		log.Warn("challenge is expired or exhausted")
+		return fail(ErrNoToken)
+	}
+
+	user, err := a.usrPrv.User(ctx, int(challenge.UserID))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user is not found")
+			return fail(ErrNoToken)
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return fail(err)
+	}
+
+	keys := []string{loginKey(user.Login)}
+	if err = a.checkLockout(ctx, keys); err != nil {
+		log.Warn("login is locked", sl.Err(err))
+		return fail(err)
+	}
+
+	ok, err := a.checkSecondFactor(ctx, challenge.UserID, code)
+	if err != nil {
+		log.Error("failed to check second factor", sl.Err(err))
+		return fail(err)
+	}
+	if !ok {
+		log.Warn("second factor code mismatched")
+		if err = a.mfaSt.FailMFAChallenge(ctx, challenge.Hash); err != nil {
+			log.Error("failed to count attempt", sl.Err(err))
+		}
+		a.registerLoginFailure(ctx, log, keys)
+
+		return fail(ErrInvalidCode)
+	}
+
+	deleted, err := a.mfaSt.DeleteMFAChallenge(ctx, challenge.Hash)
+	if err != nil {
+		log.Error("failed to delete challenge", sl.Err(err))
+		return fail(err)
+	}
+	if !deleted {
+		log.Warn("challenge is already completed")
+		return fail(ErrNoToken)
+	}
+	a.resetLoginFailures(ctx, log, user.Login)
+
+	return user, nil
+}
+
+// mfaChallenge issues challenge of two-step login if the user has confirmed
+// second factor. It returns nil if the second factor is not required
+func (a *Auth) mfaChallenge(ctx context.Context, user models.User) (*MFARequiredError, error) {
+	factor, err := a.mfaSt.TOTP(ctx, user.UUID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+	if !factor.Confirmed {
+		return nil, nil
+	}
+
+	token, err := opaque.NewToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	expiresAt := time.Now().Add(a.mfa.ChallengeTTL)
+	err = a.mfaSt.SaveMFAChallenge(ctx, models.MFAChallenge{
+		Hash:      opaque.Hash(token),
+		UserID:    user.UUID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save challenge: %w", err)
+	}
+
+	return &MFARequiredError{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// checkSecondFactor checks the code as TOTP code and, if it does not look like
+// one, as recovery code. Accepted codes are not accepted again
+func (a *Auth) checkSecondFactor(ctx context.Context, uuid uint64, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		factor, err := a.mfaSt.TOTP(ctx, uuid)
+		if err != nil {
+			return false, fmt.Errorf("failed to get totp: %w", err)
+		}
+
+		step, ok := totp.Validate(factor.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		return a.mfaSt.AdvanceTOTPStep(ctx, uuid, step)
+	}
+
+	return a.mfaSt.UseRecoveryCode(ctx, uuid, opaque.Hash(normalizeRecoveryCode(code)))
+}
+
+// newRecoveryCodes generates recovery codes and their hashes
+func newRecoveryCodes() ([]string, [][]byte, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([][]byte, recoveryCodesCount)
+	for i := range codes {
+		raw := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		code := encoding.EncodeToString(raw)
+		codes[i] = code[:len(code)/2] + "-" + code[len(code)/2:]
+		hashes[i] = opaque.Hash(code)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode removes separators and case differences users may
+// introduce when typing recovery code
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(code, "-", ""))
+}
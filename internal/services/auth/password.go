@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/lib/logger/sl"
+	"context"
+	"log/slog"
+)
+
+// rehashPassword replaces outdated hash of the user password with a new one.
+// The hash is replaced only if the password was not changed meanwhile.
+// Failures are logged and do not break login
+func (a *Auth) rehashPassword(
+	ctx context.Context,
+	log *slog.Logger,
+	user models.User,
+	password string,
+) {
+	passHash, err := a.passwords.Hash(password)
+	if err != nil {
+		log.Error("failed to compute hash", sl.Err(err))
+		return
+	}
+
+	replaced, err := a.usrSv.ReplacePassword(ctx, user.UUID, user.PassHash, passHash)
+	if err != nil {
+		log.Error("failed to rehash password", sl.Err(err))
+		return
+	}
+	if !replaced {
+		log.Warn("password is changed before rehash")
+		return
+	}
+
+	log.Info("password is rehashed", slog.Uint64("uuid", user.UUID))
+}
+
+// checkPassword checks new password against the password policy. Broken rules
+// are returned as *WeakPasswordError
+func (a *Auth) checkPassword(log *slog.Logger, password string, identities ...string) error {
+	violations := a.policy.Check(password, identities...)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	rules := make([]string, len(violations))
+	for i, v := range violations {
+		rules[i] = v.Rule
+	}
+	log.Warn("password breaks policy", slog.Any("rules", rules))
+
+	return &WeakPasswordError{Violations: violations}
+}
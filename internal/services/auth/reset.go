@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"Service/internal/domain/models"
+	e "Service/internal/lib/errors"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/lib/opaque"
+	"Service/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RequestPasswordReset sends one-time reset token to the owner of the email.
+// Unknown emails, requests within reset interval and delivery failures are not
+// reported to the caller, so the response does not reveal whether the email is
+// registered. The interval keeps repeated requests from invalidating the token
+// sent before
+func (a *Auth) RequestPasswordReset(
+	ctx context.Context,
+	email string,
+) error {
+	const op = "auth.RequestPasswordReset"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to request password reset")
+
+	user, err := a.usrPrv.UserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user is not found")
+			return nil
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return e.Fail(op, err)
+	}
+	log = log.With(slog.Uint64("uuid", user.UUID))
+
+	createdAt, err := a.resetSt.ResetTokenCreatedAt(ctx, user.UUID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Error("failed to get last reset token", sl.Err(err))
+		return e.Fail(op, err)
+	}
+	if err == nil && time.Since(createdAt) < a.resetInterval {
+		log.Warn("reset token is requested too often")
+		return nil
+	}
+
+	if err = a.sendResetToken(ctx, user); err != nil {
+		log.Error("failed to send reset token", sl.Err(err))
+		return nil
+	}
+
+	log.Info("reset token is sent")
+	return nil
+}
+
+// ConfirmPasswordReset sets new password of the reset token owner. The token
+// is usable once. All sessions of the user are revoked
+func (a *Auth) ConfirmPasswordReset(
+	ctx context.Context,
+	token, newPassword string,
+) error {
+	const op = "auth.ConfirmPasswordReset"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to confirm password reset")
+
+	hash := opaque.Hash(token)
+	uuid, err := a.resetSt.ResetTokenOwner(ctx, hash, time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("reset token is invalid, expired or used")
+			return e.Fail(op, ErrNoToken)
+		}
+
+		log.Error("failed to get reset token", sl.Err(err))
+		return e.Fail(op, err)
+	}
+	log = log.With(slog.Uint64("uuid", uuid))
+
+	user, err := a.usrPrv.User(ctx, int(uuid))
+	if err != nil {
+		log.Error("failed to get user", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	// the policy is checked before the token is used, so the user is able to
+	// retry with another password
+	if err = a.checkPassword(log, newPassword, user.Login, user.Email); err != nil {
+		return e.Fail(op, err)
+	}
+
+	if _, err = a.resetSt.UseResetToken(ctx, hash, time.Now()); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("reset token is used meanwhile")
+			return e.Fail(op, ErrNoToken)
+		}
+
+		log.Error("failed to use reset token", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	passHash, err := a.passwords.Hash(newPassword)
+	if err != nil {
+		log.Error("failed to compute hash", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	if err = a.usrSv.UpdatePassword(ctx, uuid, passHash); err != nil {
+		log.Error("failed to update password", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	if _, err = a.tknPrv.RevokeUserTokens(ctx, uuid); err != nil {
+		log.Error("failed to revoke user tokens", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	if err = a.revokeUserAccess(ctx, uuid, ""); err != nil {
+		log.Error("failed to revoke access tokens", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	log.Info("password is reset")
+	return nil
+}
+
+// sendResetToken issues reset token for the user and delivers it. Previous
+// reset tokens of the user stop working
+func (a *Auth) sendResetToken(ctx context.Context, user models.User) error {
+	token, err := opaque.NewToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	now := time.Now()
+	err = a.resetSt.SaveResetToken(ctx, models.ResetToken{
+		Hash:      opaque.Hash(token),
+		UserID:    user.UUID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.resetTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save reset token: %w", err)
+	}
+
+	err = a.notifier.Notify(ctx, models.Notification{
+		Kind:    models.NotificationPasswordReset,
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Use the token to reset password of %s: %s\nThe token expires in %s",
+			user.Login, token, a.resetTTL,
+		),
+		Secret: token,
+		Time:   now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to deliver reset token: %w", err)
+	}
+
+	return nil
+}
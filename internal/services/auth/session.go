@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"Service/internal/domain/models"
+	e "Service/internal/lib/errors"
+	"Service/internal/lib/jwt"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/lib/opaque"
+	"Service/internal/storage"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// newSessionID generates id of a new session. Refresh tokens of a session
+// form a family: every token is rotated from the one issued at login
+func newSessionID() string {
+	return rand.Text()
+}
+
+// StartClientSession starts new session of the user on the device on behalf
+// of the client. Tokens of the session carry the grant
+func (a *Auth) StartClientSession(
+	ctx context.Context,
+	user models.User,
+	device models.Device,
+	grant models.Grant,
+) (models.TokensPair, error) {
+	const op = "auth.StartClientSession"
+	log := a.log.With(
+		slog.String("op", op),
+		slog.Uint64("uuid", user.UUID),
+		slog.String("client", grant.ClientID),
+	)
+
+	tokens, err := a.startSession(ctx, user, device, grant)
+	if err != nil {
+		log.Error("failed to start session", sl.Err(err))
+		return models.TokensPair{}, e.Fail(op, err)
+	}
+
+	log.Info("client session is started")
+	return tokens, nil
+}
+
+// startSession starts new session of the user on the device and issues the
+// first tokens pair of the session
+func (a *Auth) startSession(
+	ctx context.Context,
+	user models.User,
+	device models.Device,
+	grant models.Grant,
+) (models.TokensPair, error) {
+	now := time.Now()
+	session := models.Session{
+		ID:              newSessionID(),
+		UserID:          user.UUID,
+		Device:          device,
+		CreatedAt:       now,
+		LastRefreshedAt: now,
+	}
+
+	if err := a.tknPrv.SaveSession(ctx, session); err != nil {
+		return models.TokensPair{}, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return a.issueTokens(ctx, user, session.ID, grant)
+}
+
+// issueTokens creates new tokens pair for the user within the session. Only
+// hash of the refresh token is stored. Roles are read on every issue, so
+// refreshed tokens carry current roles
+func (a *Auth) issueTokens(
+	ctx context.Context,
+	user models.User,
+	sessionID string,
+	grant models.Grant,
+) (models.TokensPair, error) {
+	now := time.Now()
+
+	roles, err := a.usrPrv.UserRoles(ctx, user.UUID)
+	if err != nil {
+		return models.TokensPair{}, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	accessToken, claims, err := a.tokens.NewAccess(jwt.Claims{
+		UUID:      user.UUID,
+		Login:     user.Login,
+		SessionID: sessionID,
+		Scope:     strings.Join(grant.Scopes, " "),
+		ClientID:  grant.ClientID,
+		Roles:     roles,
+	}, a.tokenTTL)
+	if err != nil {
+		return models.TokensPair{}, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := opaque.NewToken()
+	if err != nil {
+		return models.TokensPair{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	err = a.tknPrv.StoreToken(ctx, models.TrackedToken{
+		Hash:      opaque.Hash(refreshToken),
+		UserID:    user.UUID,
+		SessionID: sessionID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(a.refreshTTL),
+		Access: models.AccessTokenRef{
+			JTI:       claims.ID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		},
+		Grant: grant,
+	})
+	if err != nil {
+		return models.TokensPair{}, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return models.TokensPair{
+		AccessToken: models.Token{
+			Type: models.AccessToken,
+			Val:  accessToken,
+		},
+		RefreshToken: models.Token{
+			Type: models.RefreshToken,
+			Val:  refreshToken,
+		},
+	}, nil
+}
+
+// revokeReusedSession revokes whole session of the refresh token which was
+// presented after rotation. Such token is considered stolen, so neither its
+// holder nor the legitimate user can continue the session
+func (a *Auth) revokeReusedSession(
+	ctx context.Context,
+	log *slog.Logger,
+	stored models.TrackedToken,
+) {
+	log = log.With(
+		slog.Uint64("uuid", stored.UserID),
+		slog.String("session", stored.SessionID),
+	)
+	log.Warn("refresh token reuse detected")
+
+	n, err := a.tknPrv.RevokeSession(ctx, stored.SessionID)
+	if err != nil {
+		log.Error("failed to revoke session", sl.Err(err))
+	} else {
+		log.Info("session is revoked", slog.Int64("tokens", n))
+	}
+
+	if err = a.revokeSessionAccess(ctx, stored.SessionID); err != nil {
+		log.Error("failed to revoke access tokens", sl.Err(err))
+	}
+
+	a.events.Emit(ctx, models.SecurityEvent{
+		Type:      models.EventRefreshTokenReuse,
+		UserID:    stored.UserID,
+		SessionID: stored.SessionID,
+		Time:      time.Now(),
+	})
+}
+
+// authenticate checks the access token and returns its claims and owner.
+// Invalid and revoked tokens, tokens of revoked sessions and tokens issued to
+// clients are reported as ErrNoToken
+func (a *Auth) authenticate(
+	ctx context.Context,
+	log *slog.Logger,
+	accessToken string,
+) (jwt.Claims, models.User, error) {
+	claims, err := a.tokens.ParseToken(accessToken)
+	if err != nil {
+		log.Warn("token is invalid", sl.Err(err))
+		return jwt.Claims{}, models.User{}, ErrNoToken
+	}
+	if a.revoked.IsRevoked(claims.ID) {
+		log.Warn("token is in revocation list")
+		return jwt.Claims{}, models.User{}, ErrNoToken
+	}
+	if claims.ClientID != "" {
+		log.Warn("token is issued to client", slog.String("client", claims.ClientID))
+		return jwt.Claims{}, models.User{}, ErrNoToken
+	}
+
+	active, err := a.tknPrv.SessionActive(ctx, claims.SessionID)
+	if err != nil {
+		log.Error("failed to check session", sl.Err(err))
+		return jwt.Claims{}, models.User{}, err
+	}
+	if !active {
+		log.Warn("session of the token is revoked")
+		return jwt.Claims{}, models.User{}, ErrNoToken
+	}
+
+	user, err := a.usrPrv.User(ctx, int(claims.UUID))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("owner of the token is not found")
+			return jwt.Claims{}, models.User{}, ErrNoToken
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return jwt.Claims{}, models.User{}, err
+	}
+
+	return claims, user, nil
+}
+
+// revokeSessionAccess adds outstanding access tokens of the session to the
+// revocation list. Expired tokens are outstanding as well while they are
+// accepted within leeway
+func (a *Auth) revokeSessionAccess(ctx context.Context, sessionID string) error {
+	refs, err := a.tknPrv.SessionAccessTokens(ctx, sessionID, time.Now().Add(-a.tokens.Leeway))
+	if err != nil {
+		return fmt.Errorf("failed to get access tokens: %w", err)
+	}
+
+	return a.revoked.Revoke(ctx, refs...)
+}
+
+// revokeUserAccess adds outstanding access tokens of the user to the
+// revocation list. Tokens of the kept session are left valid
+func (a *Auth) revokeUserAccess(ctx context.Context, uuid uint64, keptSession string) error {
+	refs, err := a.tknPrv.UserAccessTokens(ctx, uuid, keptSession, time.Now().Add(-a.tokens.Leeway))
+	if err != nil {
+		return fmt.Errorf("failed to get access tokens: %w", err)
+	}
+
+	return a.revoked.Revoke(ctx, refs...)
+}
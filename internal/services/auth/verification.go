@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"Service/internal/domain/models"
+	e "Service/internal/lib/errors"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/lib/opaque"
+	"Service/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// VerificationPolicy configures verification of user emails
+type VerificationPolicy struct {
+	// TTL is lifetime of verification codes
+	TTL time.Duration
+	// ResendInterval is minimal interval between codes sent to the same user
+	ResendInterval time.Duration
+	// Required blocks login of users with unverified email
+	Required bool
+}
+
+// VerifyEmail marks email of the code owner as verified. The code is usable
+// once
+func (a *Auth) VerifyEmail(
+	ctx context.Context,
+	code string,
+) error {
+	const op = "auth.VerifyEmail"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to verify email")
+
+	uuid, err := a.verifySt.UseVerification(ctx, opaque.Hash(code), time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("verification code is invalid or expired")
+			return e.Fail(op, ErrNoToken)
+		}
+
+		log.Error("failed to use verification code", sl.Err(err))
+		return e.Fail(op, err)
+	}
+	log = log.With(slog.Uint64("uuid", uuid))
+
+	if err = a.usrSv.SetEmailVerified(ctx, uuid); err != nil {
+		log.Error("failed to mark email as verified", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	log.Info("email is verified")
+	return nil
+}
+
+// ResendVerification sends new verification code to the email. Codes are sent
+// not more often than once per resend interval. Unknown and already verified
+// emails, requests within the interval and delivery failures are not reported
+// to the caller, so the response does not reveal state of the email
+func (a *Auth) ResendVerification(
+	ctx context.Context,
+	email string,
+) error {
+	const op = "auth.ResendVerification"
+	log := a.log.With(slog.String("op", op))
+	log.Info("starting to resend verification code")
+
+	user, err := a.usrPrv.UserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user is not found")
+			return nil
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return e.Fail(op, err)
+	}
+	log = log.With(slog.Uint64("uuid", user.UUID))
+
+	if user.EmailVerified {
+		log.Warn("email is already verified")
+		return nil
+	}
+
+	sentAt, err := a.verifySt.VerificationSentAt(ctx, user.UUID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Error("failed to get last verification", sl.Err(err))
+		return e.Fail(op, err)
+	}
+	if err == nil && time.Since(sentAt) < a.verification.ResendInterval {
+		log.Warn("verification code is requested too often")
+		return nil
+	}
+
+	if err = a.sendVerification(ctx, user); err != nil {
+		log.Error("failed to send verification code", sl.Err(err))
+		return nil
+	}
+
+	log.Info("verification code is sent")
+	return nil
+}
+
+// sendVerification issues verification code for the user and delivers it.
+// Previous code of the user stops working
+func (a *Auth) sendVerification(ctx context.Context, user models.User) error {
+	code, err := opaque.NewToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	now := time.Now()
+	err = a.verifySt.SaveVerification(ctx, models.EmailVerification{
+		Hash:      opaque.Hash(code),
+		UserID:    user.UUID,
+		SentAt:    now,
+		ExpiresAt: now.Add(a.verification.TTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save verification code: %w", err)
+	}
+
+	err = a.notifier.Notify(ctx, models.Notification{
+		Kind:    models.NotificationEmailVerification,
+		To:      user.Email,
+		Subject: "Email verification",
+		Body: fmt.Sprintf(
+			"Use the code to verify email of %s: %s\nThe code expires in %s",
+			user.Login, code, a.verification.TTL,
+		),
+		Secret: code,
+		Time:   now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to deliver verification code: %w", err)
+	}
+
+	return nil
+}
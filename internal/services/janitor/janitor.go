@@ -0,0 +1,236 @@
+package janitor
+
+import (
+	"Service/internal/lib/logger/sl"
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+var (
+	// tokensRemoved counts refresh token rows removed since start
+	tokensRemoved = expvar.NewInt("janitor_tokens_removed")
+	// sessionsRemoved counts sessions without refresh tokens removed since start
+	sessionsRemoved = expvar.NewInt("janitor_sessions_removed")
+	// revokedRemoved counts revocation list entries removed since start
+	revokedRemoved = expvar.NewInt("janitor_revoked_access_tokens_removed")
+	// resetsRemoved counts password reset tokens removed since start
+	resetsRemoved = expvar.NewInt("janitor_password_resets_removed")
+	// verificationsRemoved counts email verification codes removed since start
+	verificationsRemoved = expvar.NewInt("janitor_verifications_removed")
+	// challengesRemoved counts expired login challenges removed since start
+	challengesRemoved = expvar.NewInt("janitor_mfa_challenges_removed")
+	// attemptsRemoved counts expired failed login counters removed since start
+	attemptsRemoved = expvar.NewInt("janitor_login_attempts_removed")
+	// codesRemoved counts expired authorization codes removed since start
+	codesRemoved = expvar.NewInt("janitor_authorization_codes_removed")
+	// runs counts completed purge runs
+	runs = expvar.NewInt("janitor_runs")
+	// failures counts failed purge runs
+	failures = expvar.NewInt("janitor_failures")
+)
+
+// sessionGrace is the age of sessions without tokens which are kept, since
+// the first token of a session is stored after the session
+const sessionGrace = time.Minute
+
+type Storage interface {
+	PurgeTokens(ctx context.Context, before time.Time, limit int) (int64, error)
+	PurgeSessions(ctx context.Context, before time.Time, limit int) (int64, error)
+	PurgeRevokedAccessTokens(ctx context.Context, before time.Time, limit int) (int64, error)
+	PurgeResetTokens(ctx context.Context, before time.Time, limit int) (int64, error)
+	PurgeVerifications(ctx context.Context, before time.Time, limit int) (int64, error)
+	PurgeMFAChallenges(ctx context.Context, before time.Time, limit int) (int64, error)
+	PurgeLoginAttempts(ctx context.Context, before time.Time, limit int) (int64, error)
+	PurgeAuthorizationCodes(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Janitor periodically removes expired and revoked tokens from storage
+type Janitor struct {
+	log       *slog.Logger
+	st        Storage
+	interval  time.Duration
+	batchSize int
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// New creates janitor purging storage every interval in batches of batchSize
+// rows. Both of them must be positive
+func New(
+	log *slog.Logger,
+	st Storage,
+	interval time.Duration,
+	batchSize int,
+) (*Janitor, error) {
+	const op = "janitor.New"
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("%s: interval must be positive, got %s", op, interval)
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("%s: batch size must be positive, got %d", op, batchSize)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Janitor{
+		log:       log,
+		st:        st,
+		interval:  interval,
+		batchSize: batchSize,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// Start starts purging storage every interval in background until Stop is
+// called
+func (j *Janitor) Start() {
+	const op = "janitor.Start"
+	j.log.Info(
+		"starting janitor",
+		slog.String("op", op),
+		slog.Duration("interval", j.interval),
+	)
+
+	go j.run()
+}
+
+// Stop stops the janitor and waits for the current purge to finish
+func (j *Janitor) Stop() {
+	const op = "janitor.Stop"
+	j.log.Info("stopping janitor", slog.String("op", op))
+
+	j.cancel()
+	<-j.done
+}
+
+func (j *Janitor) run() {
+	defer close(j.done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.purge(j.ctx)
+
+		select {
+		case <-j.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge removes expired and revoked rows batch by batch
+func (j *Janitor) purge(ctx context.Context) {
+	const op = "janitor.purge"
+	log := j.log.With(slog.String("op", op))
+
+	now := time.Now()
+
+	tokens, err := j.purgeBatches(ctx, now, j.st.PurgeTokens)
+	tokensRemoved.Add(tokens)
+	if err != nil {
+		failures.Add(1)
+		log.Error("failed to purge tokens", sl.Err(err))
+		return
+	}
+
+	sessions, err := j.purgeBatches(ctx, now.Add(-sessionGrace), j.st.PurgeSessions)
+	sessionsRemoved.Add(sessions)
+	if err != nil {
+		failures.Add(1)
+		log.Error("failed to purge sessions", sl.Err(err))
+		return
+	}
+
+	revoked, err := j.purgeBatches(ctx, now, j.st.PurgeRevokedAccessTokens)
+	revokedRemoved.Add(revoked)
+	if err != nil {
+		failures.Add(1)
+		log.Error("failed to purge revocation list", sl.Err(err))
+		return
+	}
+
+	resets, err := j.purgeBatches(ctx, now, j.st.PurgeResetTokens)
+	resetsRemoved.Add(resets)
+	if err != nil {
+		failures.Add(1)
+		log.Error("failed to purge password resets", sl.Err(err))
+		return
+	}
+
+	verifications, err := j.purgeBatches(ctx, now, j.st.PurgeVerifications)
+	verificationsRemoved.Add(verifications)
+	if err != nil {
+		failures.Add(1)
+		log.Error("failed to purge email verifications", sl.Err(err))
+		return
+	}
+
+	challenges, err := j.purgeBatches(ctx, now, j.st.PurgeMFAChallenges)
+	challengesRemoved.Add(challenges)
+	if err != nil {
+		failures.Add(1)
+		log.Error("failed to purge mfa challenges", sl.Err(err))
+		return
+	}
+
+	attempts, err := j.purgeBatches(ctx, now, j.st.PurgeLoginAttempts)
+	attemptsRemoved.Add(attempts)
+	if err != nil {
+		failures.Add(1)
+		log.Error("failed to purge login attempts", sl.Err(err))
+		return
+	}
+
+	codes, err := j.purgeBatches(ctx, now, j.st.PurgeAuthorizationCodes)
+	codesRemoved.Add(codes)
+	if err != nil {
+		failures.Add(1)
+		log.Error("failed to purge authorization codes", sl.Err(err))
+		return
+	}
+
+	runs.Add(1)
+	log.Debug(
+		"storage is purged",
+		slog.Int64("tokens", tokens),
+		slog.Int64("sessions", sessions),
+		slog.Int64("revoked-access-tokens", revoked),
+		slog.Int64("password-resets", resets),
+		slog.Int64("verifications", verifications),
+		slog.Int64("mfa-challenges", challenges),
+		slog.Int64("login-attempts", attempts),
+		slog.Int64("authorization-codes", codes),
+	)
+}
+
+// purgeBatches calls purge until it removes less rows than the batch size. It
+// keeps write transactions short, so requests are not blocked for long
+func (j *Janitor) purgeBatches(
+	ctx context.Context,
+	before time.Time,
+	purge func(ctx context.Context, before time.Time, limit int) (int64, error),
+) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		n, err := purge(ctx, before, j.batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+
+		if n < int64(j.batchSize) {
+			break
+		}
+	}
+
+	return total, nil
+}
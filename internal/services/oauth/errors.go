@@ -0,0 +1,40 @@
+package oauth
+
+import "errors"
+
+var (
+	// ErrInvalidClient is returned when the client is not registered. Such
+	// requests are not redirected back to the client
+	ErrInvalidClient = errors.New("client is not registered")
+	// ErrInvalidRedirectURI is returned when the redirect URI is not
+	// registered for the client. Such requests are not redirected back
+	ErrInvalidRedirectURI = errors.New("redirect uri is not registered")
+)
+
+// Error codes of RFC 6749
+const (
+	CodeInvalidRequest          = "invalid_request"
+	CodeInvalidClient           = "invalid_client"
+	CodeInvalidGrant            = "invalid_grant"
+	CodeInvalidScope            = "invalid_scope"
+	CodeUnauthorizedClient      = "unauthorized_client"
+	CodeAccessDenied            = "access_denied"
+	CodeUnsupportedResponseType = "unsupported_response_type"
+	CodeUnsupportedGrantType    = "unsupported_grant_type"
+	CodeServerError             = "server_error"
+)
+
+// Error is an error reported to the client with one of error codes of
+// RFC 6749
+type Error struct {
+	Code        string
+	Description string
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func fail(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
@@ -0,0 +1,490 @@
+package oauth
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/lib/jwt"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/lib/opaque"
+	"Service/internal/lib/password"
+	"Service/internal/services/auth"
+	"Service/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	ResponseTypeCode = "code"
+
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+
+	// ScopeOpenID makes the request an OpenID Connect request, an ID token is
+	// issued along with access token. Profile and email scopes add claims
+	// about the user to ID tokens and userinfo responses
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// defaultGrants are grants of clients registered without grants
+var defaultGrants = []string{GrantAuthorizationCode, GrantRefreshToken}
+
+type CodeStorage interface {
+	SaveAuthorizationCode(ctx context.Context, code models.AuthorizationCode) error
+	UseAuthorizationCode(ctx context.Context, hash []byte) (models.AuthorizationCode, error)
+}
+
+type UserProvider interface {
+	User(ctx context.Context, key interface{}) (models.User, error)
+}
+
+// SessionProvider starts and refreshes sessions of users on behalf of clients
+type SessionProvider interface {
+	StartClientSession(
+		ctx context.Context,
+		user models.User,
+		device models.Device,
+		grant models.Grant,
+	) (models.TokensPair, error)
+	RefreshClientTokens(
+		ctx context.Context,
+		clientID, refreshToken string,
+	) (models.TokensPair, models.Grant, error)
+}
+
+// SecretVerifier verifies secrets of confidential clients against their
+// hashes
+type SecretVerifier interface {
+	Verify(hash []byte, secret string) (rehash bool, err error)
+}
+
+// TokenIssuer issues access tokens of clients acting on their own behalf and
+// ID tokens of users
+type TokenIssuer interface {
+	NewClientAccess(clientID string, scopes []string, exp time.Duration) (string, jwt.Claims, error)
+	NewID(claims jwt.IDClaims, clientID string, authTime time.Time, exp time.Duration) (string, error)
+}
+
+type OAuth struct {
+	log      *slog.Logger
+	clients  map[string]models.OAuthClient
+	codes    CodeStorage
+	usrPrv   UserProvider
+	sessions SessionProvider
+	secrets  SecretVerifier
+	tokens   TokenIssuer
+	codeTTL  time.Duration
+	tokenTTL time.Duration
+}
+
+// New creates authorization server of the registered clients. Redirect URIs,
+// grants and secret hashes of the clients are validated
+func New(
+	log *slog.Logger,
+	clients []models.OAuthClient,
+	codes CodeStorage,
+	usrPrv UserProvider,
+	sessions SessionProvider,
+	secrets SecretVerifier,
+	tokens TokenIssuer,
+	codeTTL time.Duration,
+	tokenTTL time.Duration,
+) (*OAuth, error) {
+	const op = "oauth.New"
+
+	registry := make(map[string]models.OAuthClient, len(clients))
+	for _, c := range clients {
+		if len(c.Grants) == 0 {
+			c.Grants = defaultGrants
+		}
+
+		if err := validateClient(c); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if _, ok := registry[c.ID]; ok {
+			return nil, fmt.Errorf("%s: client %q is registered twice", op, c.ID)
+		}
+
+		registry[c.ID] = c
+	}
+
+	return &OAuth{
+		log:      log,
+		clients:  registry,
+		codes:    codes,
+		usrPrv:   usrPrv,
+		sessions: sessions,
+		secrets:  secrets,
+		tokens:   tokens,
+		codeTTL:  codeTTL,
+		tokenTTL: tokenTTL,
+	}, nil
+}
+
+// CheckAuthorization validates the authorization request. It returns
+// ErrInvalidClient or ErrInvalidRedirectURI if the client can not be
+// redirected to, other errors are *Error to redirect with. Scopes of the
+// request default to all scopes of the client
+func (o *OAuth) CheckAuthorization(req models.AuthorizationRequest) (models.OAuthClient, []string, error) {
+	client, ok := o.clients[req.ClientID]
+	if !ok {
+		return models.OAuthClient{}, nil, ErrInvalidClient
+	}
+
+	if !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		return models.OAuthClient{}, nil, ErrInvalidRedirectURI
+	}
+
+	if !slices.Contains(client.Grants, GrantAuthorizationCode) {
+		return client, nil, fail(CodeUnauthorizedClient, "authorization code grant is not allowed")
+	}
+
+	if req.ResponseType != ResponseTypeCode {
+		return client, nil, fail(CodeUnsupportedResponseType, "only code response type is supported")
+	}
+
+	if req.CodeChallengeMethod != MethodS256 || !validPKCEString(req.CodeChallenge) {
+		return client, nil, fail(CodeInvalidRequest, "S256 code challenge is required")
+	}
+
+	scopes, err := allowedScopes(client, req.Scopes)
+	if err != nil {
+		return client, nil, err
+	}
+
+	return client, scopes, nil
+}
+
+// IssueCode issues authorization code of the request on behalf of the user.
+// The user has to be authenticated by the caller
+func (o *OAuth) IssueCode(
+	ctx context.Context,
+	req models.AuthorizationRequest,
+	user models.User,
+	device models.Device,
+) (string, error) {
+	const op = "oauth.IssueCode"
+	log := o.log.With(
+		slog.String("op", op),
+		slog.String("client", req.ClientID),
+		slog.Uint64("uuid", user.UUID),
+	)
+
+	_, scopes, err := o.CheckAuthorization(req)
+	if err != nil {
+		log.Warn("invalid authorization request", sl.Err(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	code, err := opaque.NewToken()
+	if err != nil {
+		log.Error("failed to generate code", sl.Err(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = o.codes.SaveAuthorizationCode(ctx, models.AuthorizationCode{
+		Hash:          opaque.Hash(code),
+		ClientID:      req.ClientID,
+		UserID:        user.UUID,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+		Nonce:         req.Nonce,
+		AuthTime:      time.Now(),
+		Device:        device,
+		ExpiresAt:     time.Now().Add(o.codeTTL),
+	})
+	if err != nil {
+		log.Error("failed to save code", sl.Err(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("authorization code is issued")
+	return code, nil
+}
+
+// ExchangeCode exchanges authorization code for tokens. The code is usable
+// once by the client it is issued to, with the same redirect URI and the
+// verifier of its code challenge. ID token is issued if openid scope is
+// granted
+func (o *OAuth) ExchangeCode(
+	ctx context.Context,
+	clientID, secret, code, redirectURI, verifier string,
+) (models.OAuthTokens, error) {
+	const op = "oauth.ExchangeCode"
+	log := o.log.With(slog.String("op", op), slog.String("client", clientID))
+	log.Info("starting to exchange code")
+
+	if _, err := o.authenticateClient(log, clientID, secret, GrantAuthorizationCode); err != nil {
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stored, err := o.codes.UseAuthorizationCode(ctx, opaque.Hash(code))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("code is not found or already used")
+			return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, fail(CodeInvalidGrant, "invalid code"))
+		}
+
+		log.Error("failed to use code", sl.Err(err))
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch {
+	case stored.ClientID != clientID:
+		log.Warn("code is issued to another client", slog.String("owner", stored.ClientID))
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, fail(CodeInvalidGrant, "invalid code"))
+	case !stored.ExpiresAt.After(time.Now()):
+		log.Warn("code is expired")
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, fail(CodeInvalidGrant, "invalid code"))
+	case stored.RedirectURI != redirectURI:
+		log.Warn("redirect uri mismatched")
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, fail(CodeInvalidGrant, "redirect uri mismatched"))
+	case !verifyPKCE(stored.CodeChallenge, verifier):
+		log.Warn("code verifier mismatched")
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, fail(CodeInvalidGrant, "code verifier mismatched"))
+	}
+
+	user, err := o.usrPrv.User(ctx, int(stored.UserID))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user is not found", slog.Uint64("uuid", stored.UserID))
+			return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, fail(CodeInvalidGrant, "invalid code"))
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	grant := models.Grant{ClientID: clientID, Scopes: stored.Scopes}
+	tokens, err := o.sessions.StartClientSession(ctx, user, stored.Device, grant)
+	if err != nil {
+		log.Error("failed to start session", sl.Err(err))
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp := o.response(tokens, grant)
+	if slices.Contains(grant.Scopes, ScopeOpenID) {
+		claims := UserClaims(user, grant.Scopes)
+		claims.Nonce = stored.Nonce
+
+		resp.IDToken, err = o.tokens.NewID(claims, clientID, stored.AuthTime, o.tokenTTL)
+		if err != nil {
+			log.Error("failed to issue id token", sl.Err(err))
+			return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	log.Info("code is exchanged", slog.Uint64("uuid", user.UUID))
+	return resp, nil
+}
+
+// Refresh rotates refresh token issued to the client
+func (o *OAuth) Refresh(
+	ctx context.Context,
+	clientID, secret, refreshToken string,
+) (models.OAuthTokens, error) {
+	const op = "oauth.Refresh"
+	log := o.log.With(slog.String("op", op), slog.String("client", clientID))
+
+	if _, err := o.authenticateClient(log, clientID, secret, GrantRefreshToken); err != nil {
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tokens, grant, err := o.sessions.RefreshClientTokens(ctx, clientID, refreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoToken) ||
+			errors.Is(err, auth.ErrExpired) ||
+			errors.Is(err, auth.ErrTokenReused) ||
+			errors.Is(err, auth.ErrEmailUnverified) {
+			log.Warn("invalid refresh token", sl.Err(err))
+			return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, fail(CodeInvalidGrant, "invalid refresh token"))
+		}
+
+		log.Error("failed to refresh tokens", sl.Err(err))
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o.response(tokens, grant), nil
+}
+
+// ClientCredentials issues access token of the confidential client acting on
+// its own behalf. Scopes default to all scopes allowed to the client, no
+// refresh token is issued
+func (o *OAuth) ClientCredentials(
+	ctx context.Context,
+	clientID, secret string,
+	scopes []string,
+) (models.OAuthTokens, error) {
+	const op = "oauth.ClientCredentials"
+	log := o.log.With(slog.String("op", op), slog.String("client", clientID))
+
+	client, err := o.authenticateClient(log, clientID, secret, GrantClientCredentials)
+	if err != nil {
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	scopes, err = allowedScopes(client, scopes)
+	if err != nil {
+		log.Warn("scope is not allowed", sl.Err(err))
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, _, err := o.tokens.NewClientAccess(client.ID, scopes, o.tokenTTL)
+	if err != nil {
+		log.Error("failed to issue token", sl.Err(err))
+		return models.OAuthTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("client token is issued", slog.Any("scopes", scopes))
+	return models.OAuthTokens{
+		AccessToken: token,
+		ExpiresIn:   o.tokenTTL,
+		Scopes:      scopes,
+	}, nil
+}
+
+// authenticateClient checks that the client is registered, presents its
+// secret if it is confidential and is allowed to use the grant
+func (o *OAuth) authenticateClient(
+	log *slog.Logger,
+	clientID, secret, grant string,
+) (models.OAuthClient, error) {
+	client, ok := o.clients[clientID]
+	if !ok {
+		log.Warn("client is not registered")
+		return models.OAuthClient{}, fail(CodeInvalidClient, "client authentication failed")
+	}
+
+	switch {
+	case client.SecretHash == "" && secret != "":
+		log.Warn("secret of public client")
+		return models.OAuthClient{}, fail(CodeInvalidClient, "client authentication failed")
+	case client.SecretHash != "":
+		if _, err := o.secrets.Verify([]byte(client.SecretHash), secret); err != nil {
+			log.Warn("client secret mismatched", sl.Err(err))
+			return models.OAuthClient{}, fail(CodeInvalidClient, "client authentication failed")
+		}
+	}
+
+	if !slices.Contains(client.Grants, grant) {
+		log.Warn("grant is not allowed", slog.String("grant", grant))
+		return models.OAuthClient{}, fail(CodeUnauthorizedClient, "grant is not allowed for the client")
+	}
+
+	return client, nil
+}
+
+func (o *OAuth) response(tokens models.TokensPair, grant models.Grant) models.OAuthTokens {
+	return models.OAuthTokens{
+		AccessToken:  tokens.AccessToken.Val,
+		RefreshToken: tokens.RefreshToken.Val,
+		ExpiresIn:    o.tokenTTL,
+		Scopes:       grant.Scopes,
+	}
+}
+
+// UserClaims returns claims about the user released by the scopes. Subject of
+// the claims is the uuid of the user
+func UserClaims(user models.User, scopes []string) jwt.IDClaims {
+	var claims jwt.IDClaims
+	claims.Subject = strconv.FormatUint(user.UUID, 10)
+
+	if slices.Contains(scopes, ScopeProfile) {
+		claims.PreferredUsername = user.Login
+	}
+	if slices.Contains(scopes, ScopeEmail) {
+		claims.Email = user.Email
+		claims.EmailVerified = &user.EmailVerified
+	}
+
+	return claims
+}
+
+// allowedScopes returns requested scopes if the client is allowed all of them.
+// No scopes stand for all scopes of the client
+func allowedScopes(client models.OAuthClient, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return client.Scopes, nil
+	}
+
+	for _, scope := range requested {
+		if !slices.Contains(client.Scopes, scope) {
+			return nil, fail(CodeInvalidScope, "scope "+scope+" is not allowed")
+		}
+	}
+
+	return requested, nil
+}
+
+// validateClient checks registration of the client. Client ids must not be
+// numbers, so they are not confused with user ids in subjects of tokens
+func validateClient(c models.OAuthClient) error {
+	if c.ID == "" {
+		return errors.New("client id is empty")
+	}
+	if _, err := strconv.ParseUint(c.ID, 10, 64); err == nil {
+		return fmt.Errorf("client id %q is a number", c.ID)
+	}
+
+	for _, grant := range c.Grants {
+		switch grant {
+		case GrantAuthorizationCode, GrantRefreshToken:
+		case GrantClientCredentials:
+			if c.SecretHash == "" {
+				return fmt.Errorf("client %q: client credentials grant requires secret", c.ID)
+			}
+		default:
+			return fmt.Errorf("client %q: unknown grant %q", c.ID, grant)
+		}
+	}
+
+	if c.SecretHash != "" && !password.Known([]byte(c.SecretHash)) {
+		return fmt.Errorf("client %q: unsupported secret hash", c.ID)
+	}
+
+	for _, uri := range c.RedirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return fmt.Errorf("client %q: %w", c.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// validateRedirectURI checks that the registered redirect URI is absolute and
+// has no fragment (RFC 6749 3.1.2). Plain http is allowed for loopback only
+func validateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid redirect uri %q: %w", uri, err)
+	}
+
+	switch {
+	case !u.IsAbs():
+		return fmt.Errorf("redirect uri %q is not absolute", uri)
+	case u.Fragment != "" || u.RawFragment != "":
+		return fmt.Errorf("redirect uri %q has fragment", uri)
+	case u.Scheme == "http" && !isLoopback(u.Hostname()):
+		return fmt.Errorf("redirect uri %q uses http for non-loopback host", uri)
+	}
+
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,42 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// MethodS256 is the only code challenge method accepted, plain challenges
+// give no protection against intercepted codes
+const MethodS256 = "S256"
+
+// validPKCEString reports whether s is a code verifier or S256 challenge of
+// valid length and charset (RFC 7636 4.1)
+func validPKCEString(s string) bool {
+	if len(s) < 43 || len(s) > 128 {
+		return false
+	}
+
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '.', c == '_', c == '~':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// verifyPKCE reports whether the verifier matches S256 challenge
+func verifyPKCE(challenge, verifier string) bool {
+	if !validPKCEString(verifier) {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
@@ -0,0 +1,168 @@
+package passpolicy
+
+import (
+	"Service/internal/domain/models"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	RuleTooShort         = "PASSWORD_TOO_SHORT"
+	RuleTooLong          = "PASSWORD_TOO_LONG"
+	RuleMissingLower     = "PASSWORD_MISSING_LOWERCASE"
+	RuleMissingUpper     = "PASSWORD_MISSING_UPPERCASE"
+	RuleMissingDigit     = "PASSWORD_MISSING_DIGIT"
+	RuleMissingSymbol    = "PASSWORD_MISSING_SYMBOL"
+	RuleContainsIdentity = "PASSWORD_CONTAINS_IDENTITY"
+	RuleBreached         = "PASSWORD_BREACHED"
+)
+
+// minIdentityLength is the shortest login or email part which is looked for in
+// passwords. Shorter ones would reject too many passwords by chance
+const minIdentityLength = 3
+
+// Rules configures password policy. MinLength is counted in characters,
+// MaxLength in bytes. Zero disables the corresponding length rule
+type Rules struct {
+	MinLength      int
+	MaxLength      int
+	RequireLower   bool
+	RequireUpper   bool
+	RequireDigit   bool
+	RequireSymbol  bool
+	RejectIdentity bool
+	// BreachedList is path to the file of breached passwords, one per line.
+	// Empty lines and lines starting with "#" are skipped
+	BreachedList string
+}
+
+// Policy checks new passwords against the rules
+type Policy struct {
+	rules    Rules
+	breached map[string]struct{}
+}
+
+// New creates policy with the rules and loads the breached password list
+func New(rules Rules) (*Policy, error) {
+	if rules.MaxLength > 0 && rules.MinLength > rules.MaxLength {
+		return nil, fmt.Errorf("min length %d exceeds max length %d", rules.MinLength, rules.MaxLength)
+	}
+
+	p := &Policy{rules: rules}
+	if rules.BreachedList == "" {
+		return p, nil
+	}
+
+	breached, err := loadBreached(rules.BreachedList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load breached passwords: %w", err)
+	}
+	p.breached = breached
+
+	return p, nil
+}
+
+// Check returns every rule broken by the password. Identities are login,
+// email and other user data which must not be part of the password
+func (p *Policy) Check(password string, identities ...string) []models.PasswordViolation {
+	var violations []models.PasswordViolation
+	violate := func(rule, format string, args ...any) {
+		violations = append(violations, models.PasswordViolation{
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if n := utf8.RuneCountInString(password); n < p.rules.MinLength {
+		violate(RuleTooShort, "password must be at least %d characters", p.rules.MinLength)
+	}
+	if p.rules.MaxLength > 0 && len(password) > p.rules.MaxLength {
+		violate(RuleTooLong, "password must be at most %d bytes", p.rules.MaxLength)
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.rules.RequireLower && !lower {
+		violate(RuleMissingLower, "password must contain a lowercase letter")
+	}
+	if p.rules.RequireUpper && !upper {
+		violate(RuleMissingUpper, "password must contain an uppercase letter")
+	}
+	if p.rules.RequireDigit && !digit {
+		violate(RuleMissingDigit, "password must contain a digit")
+	}
+	if p.rules.RequireSymbol && !symbol {
+		violate(RuleMissingSymbol, "password must contain a symbol")
+	}
+
+	if p.rules.RejectIdentity && containsIdentity(password, identities) {
+		violate(RuleContainsIdentity, "password must not contain login or email")
+	}
+
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		violate(RuleBreached, "password is found in a list of breached passwords")
+	}
+
+	return violations
+}
+
+// containsIdentity reports whether the password contains any of identities or
+// the local part of an email, case-insensitively
+func containsIdentity(password string, identities []string) bool {
+	password = strings.ToLower(password)
+
+	for _, identity := range identities {
+		identity = strings.ToLower(strings.TrimSpace(identity))
+		parts := []string{identity}
+		if local, _, ok := strings.Cut(identity, "@"); ok {
+			parts = append(parts, local)
+		}
+
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= minIdentityLength && strings.Contains(password, part) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func loadBreached(path string) (map[string]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	breached := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return breached, nil
+}
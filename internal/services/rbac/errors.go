@@ -0,0 +1,10 @@
+package rbac
+
+import "errors"
+
+var (
+	ErrForbidden    = errors.New("not enough permissions")
+	ErrUserNotFound = errors.New("user is not found")
+	ErrRoleNotFound = errors.New("role is not found")
+	ErrLastAdmin    = errors.New("last admin can not be revoked")
+)
@@ -0,0 +1,247 @@
+package rbac
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+const (
+	// RoleAdmin is the role managing roles of other users. It always has
+	// PermissionManageRoles
+	RoleAdmin = "admin"
+	// PermissionManageRoles allows to assign and revoke roles
+	PermissionManageRoles = "roles:manage"
+)
+
+type Storage interface {
+	SyncRoles(ctx context.Context, roles map[string][]string) error
+	AssignRole(ctx context.Context, uuid uint64, role string) (bool, error)
+	RevokeRole(ctx context.Context, uuid uint64, role string) (bool, error)
+	RoleMembers(ctx context.Context, role string) (int, error)
+	UserRoles(ctx context.Context, uuid uint64) ([]string, error)
+	UserPermissions(ctx context.Context, uuid uint64) ([]string, error)
+}
+
+type UserProvider interface {
+	UserByUUID(ctx context.Context, uuid int) (models.User, error)
+}
+
+type RBAC struct {
+	log    *slog.Logger
+	st     Storage
+	usrPrv UserProvider
+}
+
+// New returns new instance of service layer
+func New(
+	log *slog.Logger,
+	st Storage,
+	usrPrv UserProvider,
+) *RBAC {
+	return &RBAC{
+		log:    log,
+		st:     st,
+		usrPrv: usrPrv,
+	}
+}
+
+// SyncRoles stores roles and their permissions. Admin role is added if it is
+// missing in roles
+func (r *RBAC) SyncRoles(ctx context.Context, roles map[string][]string) error {
+	const op = "rbac.SyncRoles"
+	log := r.log.With(slog.String("op", op))
+
+	synced := make(map[string][]string, len(roles)+1)
+	for role, permissions := range roles {
+		synced[role] = permissions
+	}
+	if !slices.Contains(synced[RoleAdmin], PermissionManageRoles) {
+		synced[RoleAdmin] = append(slices.Clone(synced[RoleAdmin]), PermissionManageRoles)
+	}
+
+	if err := r.st.SyncRoles(ctx, synced); err != nil {
+		log.Error("failed to sync roles", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Bootstrap assigns admin role to the user with the uuid if there is no admin
+// yet. It reports whether the role is assigned. The user is found by uuid, as
+// a login belongs to whoever signs up with it first
+func (r *RBAC) Bootstrap(ctx context.Context, uuid uint64) (bool, error) {
+	const op = "rbac.Bootstrap"
+	log := r.log.With(slog.String("op", op), slog.Uint64("uuid", uuid))
+
+	admins, err := r.st.RoleMembers(ctx, RoleAdmin)
+	if err != nil {
+		log.Error("failed to count admins", sl.Err(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if admins > 0 {
+		return false, nil
+	}
+
+	user, err := r.usrPrv.UserByUUID(ctx, int(uuid))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("bootstrap admin is not found")
+			return false, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get bootstrap admin", sl.Err(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = r.assign(ctx, user.UUID, RoleAdmin); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("bootstrap admin is assigned", slog.String("login", user.Login))
+	return true, nil
+}
+
+// AssignRole assigns the role to the user on behalf of the actor
+func (r *RBAC) AssignRole(ctx context.Context, actor, uuid uint64, role string) error {
+	const op = "rbac.AssignRole"
+	log := r.log.With(
+		slog.String("op", op),
+		slog.Uint64("actor", actor),
+		slog.Uint64("uuid", uuid),
+		slog.String("role", role),
+	)
+	log.Info("starting to assign role")
+
+	if err := r.authorize(ctx, actor); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.assign(ctx, uuid, role); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("role is assigned")
+	return nil
+}
+
+// RevokeRole revokes the role of the user on behalf of the actor. The last
+// admin keeps the role
+func (r *RBAC) RevokeRole(ctx context.Context, actor, uuid uint64, role string) error {
+	const op = "rbac.RevokeRole"
+	log := r.log.With(
+		slog.String("op", op),
+		slog.Uint64("actor", actor),
+		slog.Uint64("uuid", uuid),
+		slog.String("role", role),
+	)
+	log.Info("starting to revoke role")
+
+	if err := r.authorize(ctx, actor); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if role == RoleAdmin {
+		admins, err := r.st.RoleMembers(ctx, RoleAdmin)
+		if err != nil {
+			log.Error("failed to count admins", sl.Err(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		roles, err := r.st.UserRoles(ctx, uuid)
+		if err != nil {
+			log.Error("failed to get roles", sl.Err(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if admins == 1 && slices.Contains(roles, RoleAdmin) {
+			log.Warn("refused to revoke last admin")
+			return fmt.Errorf("%s: %w", op, ErrLastAdmin)
+		}
+	}
+
+	revoked, err := r.st.RevokeRole(ctx, uuid, role)
+	if err != nil {
+		log.Error("failed to revoke role", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("role is revoked", slog.Bool("had-role", revoked))
+	return nil
+}
+
+// Roles returns roles and permissions of the user. Users see their own roles,
+// roles of others are shown to role managers only
+func (r *RBAC) Roles(ctx context.Context, actor, uuid uint64) ([]string, []string, error) {
+	const op = "rbac.Roles"
+	log := r.log.With(slog.String("op", op), slog.Uint64("uuid", uuid))
+
+	if actor != uuid {
+		if err := r.authorize(ctx, actor); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	roles, err := r.st.UserRoles(ctx, uuid)
+	if err != nil {
+		log.Error("failed to get roles", sl.Err(err))
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	permissions, err := r.st.UserPermissions(ctx, uuid)
+	if err != nil {
+		log.Error("failed to get permissions", sl.Err(err))
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, permissions, nil
+}
+
+// authorize checks that the actor is allowed to manage roles. Permissions are
+// read from storage, so revoked roles stop working before tokens expire
+func (r *RBAC) authorize(ctx context.Context, actor uint64) error {
+	const op = "rbac.authorize"
+	log := r.log.With(slog.String("op", op), slog.Uint64("actor", actor))
+
+	permissions, err := r.st.UserPermissions(ctx, actor)
+	if err != nil {
+		log.Error("failed to get permissions", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !slices.Contains(permissions, PermissionManageRoles) {
+		log.Warn("actor is not allowed to manage roles")
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// assign stores the role of the user
+func (r *RBAC) assign(ctx context.Context, uuid uint64, role string) error {
+	const op = "rbac.assign"
+	log := r.log.With(slog.String("op", op))
+
+	_, err := r.st.AssignRole(ctx, uuid, role)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Warn("user is not found")
+			return ErrUserNotFound
+		case errors.Is(err, storage.ErrRoleNotFound):
+			log.Warn("role is not found")
+			return ErrRoleNotFound
+		}
+
+		log.Error("failed to assign role", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
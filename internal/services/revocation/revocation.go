@@ -0,0 +1,93 @@
+package revocation
+
+import (
+	"Service/internal/domain/models"
+	e "Service/internal/lib/errors"
+	"Service/internal/lib/logger/sl"
+	"Service/internal/lib/ttlset"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type Storage interface {
+	SaveRevokedAccessTokens(ctx context.Context, tokens []models.AccessTokenRef) error
+	RevokedAccessTokens(ctx context.Context) ([]models.AccessTokenRef, error)
+}
+
+// List is a revocation list of access tokens keyed by jti. It is persisted in
+// storage and mirrored in memory, so checks do not hit storage. Every entry
+// is kept only while the token it revokes is accepted, i.e. until leeway of
+// token validation passes after its expiration
+type List struct {
+	log    *slog.Logger
+	st     Storage
+	cache  *ttlset.Set
+	leeway time.Duration
+}
+
+// New creates revocation list and loads entries of accepted tokens from
+// storage
+func New(
+	ctx context.Context,
+	log *slog.Logger,
+	st Storage,
+	leeway time.Duration,
+) (*List, error) {
+	const op = "revocation.New"
+
+	tokens, err := st.RevokedAccessTokens(ctx)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	cache := ttlset.New(sweepInterval)
+	for _, token := range tokens {
+		cache.Add(token.JTI, token.ExpiresAt)
+	}
+
+	return &List{
+		log:    log,
+		st:     st,
+		cache:  cache,
+		leeway: leeway,
+	}, nil
+}
+
+// Revoke adds access tokens to the revocation list
+func (l *List) Revoke(ctx context.Context, tokens ...models.AccessTokenRef) error {
+	const op = "revocation.Revoke"
+	log := l.log.With(slog.String("op", op))
+
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// entries are stored with the time they are kept until
+	entries := make([]models.AccessTokenRef, len(tokens))
+	for i, token := range tokens {
+		entries[i] = models.AccessTokenRef{
+			JTI:       token.JTI,
+			ExpiresAt: token.ExpiresAt.Add(l.leeway),
+		}
+	}
+
+	if err := l.st.SaveRevokedAccessTokens(ctx, entries); err != nil {
+		log.Error("failed to save revoked tokens", sl.Err(err))
+		return e.Fail(op, err)
+	}
+
+	for _, entry := range entries {
+		l.cache.Add(entry.JTI, entry.ExpiresAt)
+	}
+
+	log.Info("access tokens are revoked", slog.Int("tokens", len(tokens)))
+	return nil
+}
+
+// IsRevoked reports whether the access token with the jti is revoked
+func (l *List) IsRevoked(jti string) bool {
+	return l.cache.Contains(jti)
+}
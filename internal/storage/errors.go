@@ -8,4 +8,6 @@ var (
 	ErrInvalidUserKey = errors.New("unknown type of user key")
 	ErrFollowing      = errors.New("user is already following")
 	ErrNoFollowing    = errors.New("user has not followed")
+	ErrMFAEnabled     = errors.New("second factor is already enabled")
+	ErrRoleNotFound   = errors.New("role is not found")
 )
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	e "Service/internal/lib/errors"
+)
+
+func (s *Storage) LoginAttempts(ctx context.Context, key string) (models.LoginAttempts, error) {
+	const op = "sqlite.LoginAttempts"
+	const slctQuery = `
+		SELECT key, failures, last_failure_at, locked_until FROM login_attempts WHERE key=?;
+	`
+
+	var (
+		res           models.LoginAttempts
+		lastFailureAt int64
+		lockedUntil   int64
+	)
+	err := s.db.QueryRowContext(ctx, slctQuery, key).Scan(&res.Key, &res.Failures, &lastFailureAt, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.LoginAttempts{}, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return models.LoginAttempts{}, e.Fail(op, err)
+	}
+
+	res.LastFailureAt = time.Unix(lastFailureAt, 0)
+	res.LockedUntil = time.Unix(lockedUntil, 0)
+	return res, nil
+}
+
+// AddLoginFailure counts failed login made with the key and returns number of
+// failures. Failures made before windowStart are forgotten. The counter is
+// kept in storage at least until expiresAt
+func (s *Storage) AddLoginFailure(
+	ctx context.Context,
+	key string,
+	now, windowStart, expiresAt time.Time,
+) (int, error) {
+	const op = "sqlite.AddLoginFailure"
+	const upsrtQuery = `
+		INSERT INTO login_attempts(key, failures, last_failure_at, locked_until, expires_at)
+		VALUES(?1, 1, ?2, 0, ?4)
+		ON CONFLICT(key) DO UPDATE SET
+			failures = CASE WHEN last_failure_at > ?3 THEN failures + 1 ELSE 1 END,
+			last_failure_at = ?2,
+			expires_at = MAX(expires_at, ?4)
+		RETURNING failures;
+	`
+
+	var failures int
+	err := s.db.QueryRowContext(
+		ctx,
+		upsrtQuery,
+		key,
+		now.Unix(),
+		windowStart.Unix(),
+		expiresAt.Unix(),
+	).Scan(&failures)
+	if err != nil {
+		return 0, e.Fail(op, err)
+	}
+
+	return failures, nil
+}
+
+// LockLogin locks logins with the key until the time. The counter is kept in
+// storage at least until expiresAt
+func (s *Storage) LockLogin(ctx context.Context, key string, until, expiresAt time.Time) error {
+	const op = "sqlite.LockLogin"
+	const updtQuery = `
+		UPDATE login_attempts SET locked_until=?, expires_at=MAX(expires_at, ?) WHERE key=?;
+	`
+	_, err := s.execAffected(ctx, op, updtQuery, until.Unix(), expiresAt.Unix(), key)
+	return err
+}
+
+// ResetLoginAttempts forgets failed logins made with the key
+func (s *Storage) ResetLoginAttempts(ctx context.Context, key string) error {
+	const op = "sqlite.ResetLoginAttempts"
+	const dltQuery = `
+		DELETE FROM login_attempts WHERE key=?;
+	`
+	_, err := s.execAffected(ctx, op, dltQuery, key)
+	return err
+}
+
+// PurgeLoginAttempts removes at most limit counters expired before the time
+func (s *Storage) PurgeLoginAttempts(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int64, error) {
+	const op = "sqlite.PurgeLoginAttempts"
+	const dltQuery = `
+		DELETE FROM login_attempts WHERE key IN (
+			SELECT key FROM login_attempts WHERE expires_at<=? LIMIT ?
+		);
+	`
+	return s.execAffected(ctx, op, dltQuery, before.Unix(), limit)
+}
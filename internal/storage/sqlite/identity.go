@@ -0,0 +1,187 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	e "Service/internal/lib/errors"
+	"Service/internal/lib/identity"
+	"Service/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// migrateIdentityKeys creates case-insensitive unique indexes of logins and
+// emails and fills normalized keys of users created before them. Users whose
+// key is taken by an older user keep empty key, they are reported by
+// IdentityCollisions. Users are looked up by either key, so new users whose
+// login key is email key of another user, or the reverse, are not inserted
+func migrateIdentityKeys(ctx context.Context, db *sql.DB) error {
+	const indexQuery = `
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_users_login_key ON users(login_key);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_key ON users(email_key);
+
+		CREATE TRIGGER IF NOT EXISTS trg_users_identity_keys BEFORE INSERT ON users
+		WHEN EXISTS (
+			SELECT 1 FROM users WHERE login_key = NEW.email_key OR email_key = NEW.login_key
+		)
+		BEGIN
+			SELECT RAISE(IGNORE);
+		END;
+	`
+	const slctQuery = `
+		SELECT uuid, login, email FROM users
+		WHERE login_key IS NULL OR email_key IS NULL
+		ORDER BY uuid;
+	`
+
+	if _, err := db.ExecContext(ctx, indexQuery); err != nil {
+		return err
+	}
+
+	rows, err := db.QueryContext(ctx, slctQuery)
+	if err != nil {
+		return err
+	}
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err = rows.Scan(&user.UUID, &user.Login, &user.Email); err != nil {
+			rows.Close()
+			return err
+		}
+		users = append(users, user)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		_, err = db.ExecContext(
+			ctx,
+			"UPDATE users SET login_key=? WHERE uuid=? AND login_key IS NULL;",
+			identity.Normalize(user.Login), user.UUID,
+		)
+		if err != nil && !isUniqueViolation(err) {
+			return err
+		}
+
+		_, err = db.ExecContext(
+			ctx,
+			"UPDATE users SET email_key=? WHERE uuid=? AND email_key IS NULL;",
+			identity.Normalize(user.Email), user.UUID,
+		)
+		if err != nil && !isUniqueViolation(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IdentityCollisions returns users whose login or email could not get
+// normalized key because another user holds it
+func (s *Storage) IdentityCollisions(ctx context.Context) ([]models.IdentityCollision, error) {
+	const op = "sqlite.IdentityCollisions"
+	const slctQuery = `
+		SELECT uuid, login, email, login_key IS NULL, email_key IS NULL FROM users
+		WHERE login_key IS NULL OR email_key IS NULL
+		ORDER BY uuid;
+	`
+
+	rows, err := s.db.QueryContext(ctx, slctQuery)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	var collisions []models.IdentityCollision
+	for rows.Next() {
+		var (
+			user             models.User
+			noLogin, noEmail bool
+		)
+		if err = rows.Scan(&user.UUID, &user.Login, &user.Email, &noLogin, &noEmail); err != nil {
+			rows.Close()
+			return nil, e.Fail(op, err)
+		}
+
+		if noLogin {
+			collisions = append(collisions, models.IdentityCollision{UUID: user.UUID, Field: "login", Value: user.Login})
+		}
+		if noEmail {
+			collisions = append(collisions, models.IdentityCollision{UUID: user.UUID, Field: "email", Value: user.Email})
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	for i, c := range collisions {
+		err = s.db.QueryRowContext(
+			ctx,
+			"SELECT uuid FROM users WHERE "+c.Field+"_key = ?;",
+			identity.Normalize(c.Value),
+		).Scan(&collisions[i].Owner)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, e.Fail(op, err)
+		}
+	}
+
+	return collisions, nil
+}
+
+// UserByIdentifier returns user whose login or email matches the identifier
+// after normalization. Login matches take precedence over email ones, exact
+// matches of colliding users take precedence over normalized ones
+func (s *Storage) UserByIdentifier(ctx context.Context, identifier string) (models.User, error) {
+	const op = "sqlite.UserByIdentifier"
+	const slctQuery = `
+		SELECT uuid, login, email, passhash, email_verified FROM users
+		WHERE login_key = ?1 OR email_key = ?1
+			OR (login_key IS NULL AND login = ?2)
+			OR (email_key IS NULL AND email = ?2)
+		ORDER BY CASE
+			WHEN login_key IS NULL AND login = ?2 THEN 0
+			WHEN login_key = ?1 THEN 1
+			WHEN email_key IS NULL AND email = ?2 THEN 2
+			ELSE 3
+		END
+		LIMIT 1;
+	`
+
+	user, err := s.userByKey(ctx, slctQuery, identifier)
+	if err != nil {
+		return user, e.Fail(op, err)
+	}
+
+	return user, nil
+}
+
+// userByKey runs the query selecting a user by normalized key as ?1 and exact
+// value as ?2 of the identifier
+func (s *Storage) userByKey(ctx context.Context, query, identifier string) (models.User, error) {
+	var user models.User
+
+	row := s.db.QueryRowContext(ctx, query, identity.Normalize(identifier), strings.TrimSpace(identifier))
+
+	err := row.Scan(&user.UUID, &user.Login, &user.Email, &user.PassHash, &user.EmailVerified)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, storage.ErrNotFound
+		}
+
+		return user, err
+	}
+
+	return user, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var sqlerr sqlite3.Error
+	return errors.As(err, &sqlerr) && errors.Is(sqlerr.ExtendedCode, sqlite3.ErrConstraintUnique)
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	e "Service/internal/lib/errors"
+	"Service/internal/lib/identity"
+	"context"
+)
+
+// ImportUsers inserts users with already hashed passwords in one transaction.
+// Users whose login or email is taken, also after normalization, and users
+// whose login looks like email are skipped, their logins are returned
+func (s *Storage) ImportUsers(ctx context.Context, users []models.User) ([]string, error) {
+	const op = "sqlite.ImportUsers"
+	const insrtQuery = `
+		INSERT INTO users(login, email, passhash, email_verified, login_key, email_key)
+		VALUES(?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING;
+	`
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+	defer tx.Rollback()
+
+	prep, err := tx.PrepareContext(ctx, insrtQuery)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+	defer prep.Close()
+
+	var skipped []string
+	for _, user := range users {
+		if identity.LooksLikeEmail(user.Login) {
+			skipped = append(skipped, user.Login)
+			continue
+		}
+
+		res, err := prep.ExecContext(
+			ctx,
+			user.Login, user.Email, user.PassHash, user.EmailVerified,
+			identity.Normalize(user.Login), identity.Normalize(user.Email),
+		)
+		if err != nil {
+			return nil, e.Fail(op, err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, e.Fail(op, err)
+		}
+		if n == 0 {
+			skipped = append(skipped, user.Login)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	return skipped, nil
+}
@@ -0,0 +1,226 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	e "Service/internal/lib/errors"
+)
+
+// SaveTOTP saves unconfirmed TOTP secret of the user. Pending secret of the
+// user is replaced, confirmed one is kept
+func (s *Storage) SaveTOTP(ctx context.Context, uuid uint64, secret string) error {
+	const op = "sqlite.SaveTOTP"
+	const insrtQuery = `
+		INSERT INTO totp(user_id, secret) VALUES(?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET secret=excluded.secret, last_step=0
+		WHERE confirmed=0;
+	`
+	res, err := s.db.ExecContext(ctx, insrtQuery, uuid, secret)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	if n == 0 {
+		return e.Fail(op, storage.ErrMFAEnabled)
+	}
+
+	return nil
+}
+
+func (s *Storage) TOTP(ctx context.Context, uuid uint64) (models.TOTP, error) {
+	const op = "sqlite.TOTP"
+	const slctQuery = `
+		SELECT user_id, secret, confirmed, last_step FROM totp WHERE user_id=?;
+	`
+
+	var res models.TOTP
+	err := s.db.QueryRowContext(ctx, slctQuery, uuid).Scan(
+		&res.UserID,
+		&res.Secret,
+		&res.Confirmed,
+		&res.LastStep,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TOTP{}, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return models.TOTP{}, e.Fail(op, err)
+	}
+
+	return res, nil
+}
+
+// ConfirmTOTP confirms TOTP of the user and replaces recovery codes of the
+// user with the new ones
+func (s *Storage) ConfirmTOTP(
+	ctx context.Context,
+	uuid uint64,
+	step int64,
+	recoveryCodes [][]byte,
+) error {
+	const op = "sqlite.ConfirmTOTP"
+	const updtQuery = `
+		UPDATE totp SET confirmed=1, last_step=? WHERE user_id=? AND confirmed=0;
+	`
+	const dltQuery = `
+		DELETE FROM recovery_codes WHERE user_id=?;
+	`
+	const insrtQuery = `
+		INSERT INTO recovery_codes(user_id, code_hash) VALUES(?, ?);
+	`
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, updtQuery, step, uuid)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	if n == 0 {
+		return e.Fail(op, storage.ErrNotFound)
+	}
+
+	if _, err = tx.ExecContext(ctx, dltQuery, uuid); err != nil {
+		return e.Fail(op, err)
+	}
+
+	for _, hash := range recoveryCodes {
+		if _, err = tx.ExecContext(ctx, insrtQuery, uuid, hash); err != nil {
+			return e.Fail(op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+// AdvanceTOTPStep saves the step as the last accepted one. It returns false if
+// the step is not after the last accepted one, so every code is used once
+func (s *Storage) AdvanceTOTPStep(ctx context.Context, uuid uint64, step int64) (bool, error) {
+	const op = "sqlite.AdvanceTOTPStep"
+	const updtQuery = `
+		UPDATE totp SET last_step=? WHERE user_id=? AND confirmed=1 AND last_step<?;
+	`
+	n, err := s.execAffected(ctx, op, updtQuery, step, uuid, step)
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// UseRecoveryCode marks recovery code of the user as used. It returns false if
+// there is no such unused code
+func (s *Storage) UseRecoveryCode(ctx context.Context, uuid uint64, hash []byte) (bool, error) {
+	const op = "sqlite.UseRecoveryCode"
+	const updtQuery = `
+		UPDATE recovery_codes SET used=1 WHERE user_id=? AND code_hash=? AND used=0;
+	`
+	n, err := s.execAffected(ctx, op, updtQuery, uuid, hash)
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (s *Storage) SaveMFAChallenge(ctx context.Context, challenge models.MFAChallenge) error {
+	const op = "sqlite.SaveMFAChallenge"
+	const insrtQuery = `
+		INSERT INTO mfa_challenges(token_hash, user_id, expires_at) VALUES(?, ?, ?);
+	`
+	_, err := s.db.ExecContext(ctx, insrtQuery, challenge.Hash, challenge.UserID, challenge.ExpiresAt.Unix())
+	if err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) MFAChallenge(ctx context.Context, hash []byte) (models.MFAChallenge, error) {
+	const op = "sqlite.MFAChallenge"
+	const slctQuery = `
+		SELECT token_hash, user_id, expires_at, attempts FROM mfa_challenges WHERE token_hash=?;
+	`
+
+	var (
+		res       models.MFAChallenge
+		expiresAt int64
+	)
+	err := s.db.QueryRowContext(ctx, slctQuery, hash).Scan(
+		&res.Hash,
+		&res.UserID,
+		&expiresAt,
+		&res.Attempts,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.MFAChallenge{}, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return models.MFAChallenge{}, e.Fail(op, err)
+	}
+
+	res.ExpiresAt = time.Unix(expiresAt, 0)
+	return res, nil
+}
+
+// FailMFAChallenge counts failed attempt to complete the challenge
+func (s *Storage) FailMFAChallenge(ctx context.Context, hash []byte) error {
+	const op = "sqlite.FailMFAChallenge"
+	const updtQuery = `
+		UPDATE mfa_challenges SET attempts=attempts+1 WHERE token_hash=?;
+	`
+	_, err := s.execAffected(ctx, op, updtQuery, hash)
+	return err
+}
+
+// DeleteMFAChallenge removes the challenge. It returns false if the challenge
+// has been already removed, so every challenge is completed once
+func (s *Storage) DeleteMFAChallenge(ctx context.Context, hash []byte) (bool, error) {
+	const op = "sqlite.DeleteMFAChallenge"
+	const dltQuery = `
+		DELETE FROM mfa_challenges WHERE token_hash=?;
+	`
+	n, err := s.execAffected(ctx, op, dltQuery, hash)
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// PurgeMFAChallenges removes at most limit challenges expired before the time
+func (s *Storage) PurgeMFAChallenges(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int64, error) {
+	const op = "sqlite.PurgeMFAChallenges"
+	const dltQuery = `
+		DELETE FROM mfa_challenges WHERE token_hash IN (
+			SELECT token_hash FROM mfa_challenges WHERE expires_at<=? LIMIT ?
+		);
+	`
+	return s.execAffected(ctx, op, dltQuery, before.Unix(), limit)
+}
@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	e "Service/internal/lib/errors"
+)
+
+func (s *Storage) SaveAuthorizationCode(ctx context.Context, code models.AuthorizationCode) error {
+	const op = "sqlite.SaveAuthorizationCode"
+	const insrtQuery = `
+		INSERT INTO authorization_codes(
+			code_hash, client_id, user_id, redirect_uri, scope, code_challenge,
+			nonce, auth_time, user_agent, ip, expires_at
+		)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	_, err := s.db.ExecContext(
+		ctx,
+		insrtQuery,
+		code.Hash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		strings.Join(code.Scopes, " "),
+		code.CodeChallenge,
+		code.Nonce,
+		code.AuthTime.Unix(),
+		code.Device.UserAgent,
+		code.Device.IP,
+		code.ExpiresAt.Unix(),
+	)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+// UseAuthorizationCode removes the code and returns it, so every code is
+// exchanged once
+func (s *Storage) UseAuthorizationCode(ctx context.Context, hash []byte) (models.AuthorizationCode, error) {
+	const op = "sqlite.UseAuthorizationCode"
+	const dltQuery = `
+		DELETE FROM authorization_codes WHERE code_hash=?
+		RETURNING
+			code_hash, client_id, user_id, redirect_uri, scope, code_challenge,
+			nonce, auth_time, user_agent, ip, expires_at;
+	`
+
+	var (
+		code      models.AuthorizationCode
+		scope     string
+		authTime  int64
+		expiresAt int64
+	)
+	err := s.db.QueryRowContext(ctx, dltQuery, hash).Scan(
+		&code.Hash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&scope,
+		&code.CodeChallenge,
+		&code.Nonce,
+		&authTime,
+		&code.Device.UserAgent,
+		&code.Device.IP,
+		&expiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AuthorizationCode{}, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return models.AuthorizationCode{}, e.Fail(op, err)
+	}
+
+	code.Scopes = strings.Fields(scope)
+	code.AuthTime = time.Unix(authTime, 0)
+	code.ExpiresAt = time.Unix(expiresAt, 0)
+	return code, nil
+}
+
+// PurgeAuthorizationCodes removes at most limit codes expired before the time
+func (s *Storage) PurgeAuthorizationCodes(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int64, error) {
+	const op = "sqlite.PurgeAuthorizationCodes"
+	const dltQuery = `
+		DELETE FROM authorization_codes WHERE code_hash IN (
+			SELECT code_hash FROM authorization_codes WHERE expires_at<=? LIMIT ?
+		);
+	`
+	return s.execAffected(ctx, op, dltQuery, before.Unix(), limit)
+}
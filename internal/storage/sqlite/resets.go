@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	e "Service/internal/lib/errors"
+)
+
+// SaveResetToken saves password reset token. Previous reset tokens of the user
+// are removed
+func (s *Storage) SaveResetToken(
+	ctx context.Context,
+	token models.ResetToken,
+) error {
+	const op = "sqlite.SaveResetToken"
+	const dltQuery = `
+		DELETE FROM password_resets WHERE user_id=?;
+	`
+	const insrtQuery = `
+		INSERT INTO password_resets(token_hash, user_id, created_at, expires_at) VALUES(?, ?, ?, ?);
+	`
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, dltQuery, token.UserID); err != nil {
+		return e.Fail(op, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, insrtQuery, token.Hash, token.UserID, token.CreatedAt.Unix(), token.ExpiresAt.Unix()); err != nil {
+		return e.Fail(op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+// ResetTokenCreatedAt returns time the last reset token of the user was
+// created at
+func (s *Storage) ResetTokenCreatedAt(ctx context.Context, uuid uint64) (time.Time, error) {
+	const op = "sqlite.ResetTokenCreatedAt"
+	const slctQuery = `
+		SELECT created_at FROM password_resets WHERE user_id=? ORDER BY created_at DESC LIMIT 1;
+	`
+
+	var createdAt int64
+	if err := s.db.QueryRowContext(ctx, slctQuery, uuid).Scan(&createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return time.Time{}, e.Fail(op, err)
+	}
+
+	return time.Unix(createdAt, 0), nil
+}
+
+// ResetTokenOwner returns uuid of the reset token owner without using the
+// token. Used and expired tokens are reported as not found
+func (s *Storage) ResetTokenOwner(
+	ctx context.Context,
+	hash []byte,
+	now time.Time,
+) (uint64, error) {
+	const op = "sqlite.ResetTokenOwner"
+	const slctQuery = `
+		SELECT user_id FROM password_resets
+		WHERE token_hash = ? AND used = 0 AND expires_at > ?;
+	`
+
+	var uuid uint64
+	err := s.db.QueryRowContext(ctx, slctQuery, hash, now.Unix()).Scan(&uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return 0, e.Fail(op, err)
+	}
+
+	return uuid, nil
+}
+
+// UseResetToken marks the reset token as used and returns uuid of its owner.
+// Used and expired tokens are reported as not found
+func (s *Storage) UseResetToken(
+	ctx context.Context,
+	hash []byte,
+	now time.Time,
+) (uint64, error) {
+	const op = "sqlite.UseResetToken"
+	const updtQuery = `
+		UPDATE password_resets SET used = 1
+		WHERE token_hash = ? AND used = 0 AND expires_at > ?
+		RETURNING user_id;
+	`
+
+	var uuid uint64
+	err := s.db.QueryRowContext(ctx, updtQuery, hash, now.Unix()).Scan(&uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return 0, e.Fail(op, err)
+	}
+
+	return uuid, nil
+}
+
+// PurgeResetTokens removes at most limit reset tokens which are used or
+// expired before the time
+func (s *Storage) PurgeResetTokens(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int64, error) {
+	const op = "sqlite.PurgeResetTokens"
+	const dltQuery = `
+		DELETE FROM password_resets WHERE token_hash IN (
+			SELECT token_hash FROM password_resets WHERE used=1 OR expires_at<=? LIMIT ?
+		);
+	`
+	return s.execAffected(ctx, op, dltQuery, before.Unix(), limit)
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	"context"
+	"database/sql"
+	"time"
+
+	e "Service/internal/lib/errors"
+)
+
+// SessionAccessTokens returns access tokens of the session which expire after
+// the time
+func (s *Storage) SessionAccessTokens(
+	ctx context.Context,
+	sessionID string,
+	after time.Time,
+) ([]models.AccessTokenRef, error) {
+	const op = "sqlite.SessionAccessTokens"
+	const slctQuery = `
+		SELECT access_jti, access_expires_at
+		FROM tokens
+		WHERE session_id=? AND access_jti<>'' AND access_expires_at>?;
+	`
+	rows, err := s.db.QueryContext(ctx, slctQuery, sessionID, after.Unix())
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+	defer rows.Close()
+
+	refs, err := scanAccessTokens(rows)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	return refs, nil
+}
+
+// UserAccessTokens returns access tokens of the user which expire after the
+// time. Tokens of the excepted session are skipped
+func (s *Storage) UserAccessTokens(
+	ctx context.Context,
+	uuid uint64,
+	exceptSession string,
+	after time.Time,
+) ([]models.AccessTokenRef, error) {
+	const op = "sqlite.UserAccessTokens"
+	const slctQuery = `
+		SELECT access_jti, access_expires_at
+		FROM tokens
+		WHERE user_id=? AND session_id<>? AND access_jti<>'' AND access_expires_at>?;
+	`
+	rows, err := s.db.QueryContext(ctx, slctQuery, uuid, exceptSession, after.Unix())
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+	defer rows.Close()
+
+	refs, err := scanAccessTokens(rows)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	return refs, nil
+}
+
+// SaveRevokedAccessTokens adds access tokens to the revocation list
+func (s *Storage) SaveRevokedAccessTokens(
+	ctx context.Context,
+	tokens []models.AccessTokenRef,
+) error {
+	const op = "sqlite.SaveRevokedAccessTokens"
+	const insrtQuery = `
+		INSERT OR IGNORE INTO revoked_access_tokens(jti, expires_at) VALUES(?, ?);
+	`
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	defer tx.Rollback()
+
+	prep, err := tx.PrepareContext(ctx, insrtQuery)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	defer prep.Close()
+
+	for _, token := range tokens {
+		if _, err = prep.ExecContext(ctx, token.JTI, token.ExpiresAt.Unix()); err != nil {
+			return e.Fail(op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+// RevokedAccessTokens returns entries of the revocation list which are not
+// expired yet
+func (s *Storage) RevokedAccessTokens(ctx context.Context) ([]models.AccessTokenRef, error) {
+	const op = "sqlite.RevokedAccessTokens"
+	const slctQuery = `
+		SELECT jti, expires_at FROM revoked_access_tokens WHERE expires_at>?;
+	`
+	rows, err := s.db.QueryContext(ctx, slctQuery, time.Now().Unix())
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+	defer rows.Close()
+
+	refs, err := scanAccessTokens(rows)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	return refs, nil
+}
+
+// PurgeRevokedAccessTokens removes at most limit entries of the revocation
+// list which expired before the time
+func (s *Storage) PurgeRevokedAccessTokens(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int64, error) {
+	const op = "sqlite.PurgeRevokedAccessTokens"
+	const dltQuery = `
+		DELETE FROM revoked_access_tokens WHERE jti IN (
+			SELECT jti FROM revoked_access_tokens WHERE expires_at<=? LIMIT ?
+		);
+	`
+	return s.execAffected(ctx, op, dltQuery, before.Unix(), limit)
+}
+
+func scanAccessTokens(rows *sql.Rows) ([]models.AccessTokenRef, error) {
+	refs := make([]models.AccessTokenRef, 0)
+	for rows.Next() {
+		var (
+			ref       models.AccessTokenRef
+			expiresAt int64
+		)
+		if err := rows.Scan(&ref.JTI, &expiresAt); err != nil {
+			return nil, err
+		}
+
+		ref.ExpiresAt = time.Unix(expiresAt, 0)
+		refs = append(refs, ref)
+	}
+
+	return refs, rows.Err()
+}
@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"Service/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+
+	e "Service/internal/lib/errors"
+)
+
+// SyncRoles creates the roles and replaces their permissions. Roles missing in
+// the map are kept as is
+func (s *Storage) SyncRoles(ctx context.Context, roles map[string][]string) error {
+	const op = "sqlite.SyncRoles"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	defer tx.Rollback()
+
+	for role, permissions := range roles {
+		if _, err = tx.ExecContext(ctx, "INSERT OR IGNORE INTO roles(name) VALUES(?);", role); err != nil {
+			return e.Fail(op, err)
+		}
+
+		if _, err = tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role = ?;", role); err != nil {
+			return e.Fail(op, err)
+		}
+
+		for _, permission := range permissions {
+			_, err = tx.ExecContext(
+				ctx,
+				"INSERT OR IGNORE INTO role_permissions(role, permission) VALUES(?, ?);",
+				role, permission,
+			)
+			if err != nil {
+				return e.Fail(op, err)
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+// AssignRole assigns the role to the user. It reports whether the user did
+// not have the role before
+func (s *Storage) AssignRole(ctx context.Context, uuid uint64, role string) (bool, error) {
+	const op = "sqlite.AssignRole"
+	const insrtQuery = `
+		INSERT OR IGNORE INTO user_roles(user_id, role) VALUES(?, ?);
+	`
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, e.Fail(op, err)
+	}
+	defer tx.Rollback()
+
+	if err = exists(ctx, tx, "SELECT 1 FROM users WHERE uuid = ?;", uuid, storage.ErrNotFound); err != nil {
+		return false, e.Fail(op, err)
+	}
+	if err = exists(ctx, tx, "SELECT 1 FROM roles WHERE name = ?;", role, storage.ErrRoleNotFound); err != nil {
+		return false, e.Fail(op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, insrtQuery, uuid, role)
+	if err != nil {
+		return false, e.Fail(op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, e.Fail(op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, e.Fail(op, err)
+	}
+
+	return n == 1, nil
+}
+
+// RevokeRole takes the role away from the user. It reports whether the user
+// had the role
+func (s *Storage) RevokeRole(ctx context.Context, uuid uint64, role string) (bool, error) {
+	const op = "sqlite.RevokeRole"
+	const dltQuery = `
+		DELETE FROM user_roles WHERE user_id = ? AND role = ?;
+	`
+
+	n, err := s.execAffected(ctx, op, dltQuery, uuid, role)
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// RoleMembers returns number of users having the role
+func (s *Storage) RoleMembers(ctx context.Context, role string) (int, error) {
+	const op = "sqlite.RoleMembers"
+	const slctQuery = `
+		SELECT COUNT(*) FROM user_roles WHERE role = ?;
+	`
+
+	var n int
+	if err := s.db.QueryRowContext(ctx, slctQuery, role).Scan(&n); err != nil {
+		return 0, e.Fail(op, err)
+	}
+
+	return n, nil
+}
+
+// UserRoles returns roles of the user sorted by name
+func (s *Storage) UserRoles(ctx context.Context, uuid uint64) ([]string, error) {
+	const op = "sqlite.UserRoles"
+	const slctQuery = `
+		SELECT role FROM user_roles WHERE user_id = ? ORDER BY role;
+	`
+
+	roles, err := s.strings(ctx, slctQuery, uuid)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	return roles, nil
+}
+
+// UserPermissions returns permissions granted to the user by all roles sorted
+// by name
+func (s *Storage) UserPermissions(ctx context.Context, uuid uint64) ([]string, error) {
+	const op = "sqlite.UserPermissions"
+	const slctQuery = `
+		SELECT DISTINCT p.permission FROM user_roles r
+		JOIN role_permissions p ON p.role = r.role
+		WHERE r.user_id = ?
+		ORDER BY p.permission;
+	`
+
+	permissions, err := s.strings(ctx, slctQuery, uuid)
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	return permissions, nil
+}
+
+// strings runs the query selecting a single text column
+func (s *Storage) strings(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]string, 0)
+	for rows.Next() {
+		var v string
+		if err = rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+
+	return res, rows.Err()
+}
+
+// exists returns notFound if the query selects no rows
+func exists(ctx context.Context, tx *sql.Tx, query string, arg any, notFound error) error {
+	var one int
+	err := tx.QueryRowContext(ctx, query, arg).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+
+	return err
+}
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	"context"
+	"time"
+
+	e "Service/internal/lib/errors"
+)
+
+func (s *Storage) SaveSession(ctx context.Context, session models.Session) error {
+	const op = "sqlite.SaveSession"
+	const insrtQuery = `
+		INSERT INTO sessions(id, user_id, user_agent, ip, created_at, last_refreshed_at)
+		VALUES(?, ?, ?, ?, ?, ?);
+	`
+	_, err := s.db.ExecContext(
+		ctx,
+		insrtQuery,
+		session.ID,
+		session.UserID,
+		session.Device.UserAgent,
+		session.Device.IP,
+		session.CreatedAt.Unix(),
+		session.LastRefreshedAt.Unix(),
+	)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) TouchSession(ctx context.Context, sessionID string, at time.Time) error {
+	const op = "sqlite.TouchSession"
+	const updtQuery = `
+		UPDATE sessions SET last_refreshed_at = ? WHERE id = ?;
+	`
+	if _, err := s.db.ExecContext(ctx, updtQuery, at.Unix(), sessionID); err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+// Sessions returns sessions of the user which have at least one alive refresh
+// token. The most recently refreshed sessions go first
+func (s *Storage) Sessions(ctx context.Context, uuid uint64) ([]models.Session, error) {
+	const op = "sqlite.Sessions"
+	const slctQuery = `
+		SELECT id, user_id, user_agent, ip, created_at, last_refreshed_at
+		FROM sessions
+		WHERE user_id = ? AND EXISTS(
+			SELECT 1 FROM tokens
+			WHERE tokens.session_id = sessions.id AND revoked = 0 AND expires_at > ?
+		)
+		ORDER BY last_refreshed_at DESC;
+	`
+	rows, err := s.db.QueryContext(ctx, slctQuery, uuid, time.Now().Unix())
+	if err != nil {
+		return nil, e.Fail(op, err)
+	}
+	defer rows.Close()
+
+	sessions := make([]models.Session, 0)
+	for rows.Next() {
+		var (
+			session         models.Session
+			createdAt       int64
+			lastRefreshedAt int64
+		)
+		err = rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.Device.UserAgent,
+			&session.Device.IP,
+			&createdAt,
+			&lastRefreshedAt,
+		)
+		if err != nil {
+			return nil, e.Fail(op, err)
+		}
+
+		session.CreatedAt = time.Unix(createdAt, 0)
+		session.LastRefreshedAt = time.Unix(lastRefreshedAt, 0)
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, e.Fail(op, err)
+	}
+
+	return sessions, nil
+}
+
+// PurgeSessions removes at most limit sessions created before the time which
+// have no refresh tokens left
+func (s *Storage) PurgeSessions(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int64, error) {
+	const op = "sqlite.PurgeSessions"
+	const dltQuery = `
+		DELETE FROM sessions WHERE id IN (
+			SELECT id FROM sessions
+			WHERE created_at<=? AND NOT EXISTS(
+				SELECT 1 FROM tokens WHERE tokens.session_id=sessions.id
+			)
+			LIMIT ?
+		);
+	`
+	return s.execAffected(ctx, op, dltQuery, before.Unix(), limit)
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	e "Service/internal/lib/errors"
+	"Service/internal/lib/identity"
 
 	"github.com/mattn/go-sqlite3"
 )
@@ -27,7 +28,9 @@ func New(storagePath string) *Storage {
 		panic("failed to open database: " + err.Error())
 	}
 
+	dropLegacyTokens(db)
 	initDB(db)
+	migrateDB(db)
 
 	return &Storage{
 		db: db,
@@ -58,13 +61,128 @@ func initDB(db *sql.DB) {
 			FOREIGN KEY (followee) REFERENCES users(uuid) ON DELETE CASCADE
 		);
 
-		DROP TABLE IF EXISTS tokens;
+		CREATE TABLE IF NOT EXISTS sessions (
+			id TEXT PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			user_agent TEXT NOT NULL,
+			ip TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			last_refreshed_at INTEGER NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
 
 		CREATE TABLE IF NOT EXISTS tokens (
 			id integer PRIMARY KEY,
-			refresh_token TEXT NOT NULL,
-			access_token TEXT NOT NULL
-		)
+			token_hash BLOB NOT NULL UNIQUE,
+			user_id INTEGER NOT NULL,
+			session_id TEXT NOT NULL,
+			issued_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL DEFAULT 0,
+			expires_at INTEGER NOT NULL,
+			used INTEGER NOT NULL DEFAULT 0,
+			revoked INTEGER NOT NULL DEFAULT 0,
+			access_jti TEXT NOT NULL DEFAULT '',
+			access_expires_at INTEGER NOT NULL DEFAULT 0,
+			client_id TEXT NOT NULL DEFAULT '',
+			scope TEXT NOT NULL DEFAULT '',
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_tokens_user_id ON tokens(user_id);
+		CREATE INDEX IF NOT EXISTS idx_tokens_session_id ON tokens(session_id);
+
+		CREATE TABLE IF NOT EXISTS password_resets (
+			token_hash BLOB PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL,
+			used INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_password_resets_user_id ON password_resets(user_id);
+
+		CREATE TABLE IF NOT EXISTS email_verifications (
+			user_id INTEGER PRIMARY KEY,
+			code_hash BLOB NOT NULL UNIQUE,
+			sent_at INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS totp (
+			user_id INTEGER PRIMARY KEY,
+			secret TEXT NOT NULL,
+			confirmed INTEGER NOT NULL DEFAULT 0,
+			last_step INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS recovery_codes (
+			user_id INTEGER NOT NULL,
+			code_hash BLOB NOT NULL,
+			used INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY(user_id, code_hash),
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS mfa_challenges (
+			token_hash BLOB PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS login_attempts (
+			key TEXT PRIMARY KEY,
+			failures INTEGER NOT NULL,
+			last_failure_at INTEGER NOT NULL,
+			locked_until INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+			jti TEXT PRIMARY KEY,
+			expires_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS roles (
+			name TEXT PRIMARY KEY
+		);
+
+		CREATE TABLE IF NOT EXISTS role_permissions (
+			role TEXT NOT NULL,
+			permission TEXT NOT NULL,
+			PRIMARY KEY(role, permission),
+			FOREIGN KEY (role) REFERENCES roles(name) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS user_roles (
+			user_id INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			PRIMARY KEY(user_id, role),
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE,
+			FOREIGN KEY (role) REFERENCES roles(name) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_user_roles_role ON user_roles(role);
+
+		CREATE TABLE IF NOT EXISTS authorization_codes (
+			code_hash BLOB PRIMARY KEY,
+			client_id TEXT NOT NULL,
+			user_id INTEGER NOT NULL,
+			redirect_uri TEXT NOT NULL,
+			scope TEXT NOT NULL,
+			code_challenge TEXT NOT NULL,
+			nonce TEXT NOT NULL DEFAULT '',
+			auth_time INTEGER NOT NULL DEFAULT 0,
+			user_agent TEXT NOT NULL,
+			ip TEXT NOT NULL,
+			expires_at INTEGER NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(uuid) ON DELETE CASCADE
+		);
 		`,
 	)
 	if err != nil {
@@ -72,6 +190,86 @@ func initDB(db *sql.DB) {
 	}
 }
 
+// dropLegacyTokens drops tokens table of the first version of the service,
+// which kept plain tokens and was recreated at every start. Its rows are not
+// valid refresh tokens, so the table is dropped before the current one and
+// its indexes are created
+func dropLegacyTokens(db *sql.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	legacy, err := columnExists(ctx, db, "tokens", "refresh_token")
+	if err != nil {
+		panic("dropLegacyTokens: failed to inspect table - " + err.Error())
+	}
+	if !legacy {
+		return
+	}
+
+	if _, err = db.ExecContext(ctx, "DROP TABLE tokens;"); err != nil {
+		panic("dropLegacyTokens: failed to drop table - " + err.Error())
+	}
+}
+
+// column is a column added to existing table after the table was created
+type column struct {
+	table string
+	name  string
+	def   string
+}
+
+// migrations lists columns which databases created by previous versions of
+// the service may lack
+var migrations = []column{
+	{"users", "email_verified", "INTEGER NOT NULL DEFAULT 0"},
+	{"tokens", "access_jti", "TEXT NOT NULL DEFAULT ''"},
+	{"tokens", "access_expires_at", "INTEGER NOT NULL DEFAULT 0"},
+	{"users", "login_key", "TEXT"},
+	{"users", "email_key", "TEXT"},
+	{"tokens", "client_id", "TEXT NOT NULL DEFAULT ''"},
+	{"tokens", "scope", "TEXT NOT NULL DEFAULT ''"},
+	{"authorization_codes", "nonce", "TEXT NOT NULL DEFAULT ''"},
+	{"authorization_codes", "auth_time", "INTEGER NOT NULL DEFAULT 0"},
+	{"password_resets", "created_at", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// migrateDB adds missing columns to existing tables
+func migrateDB(db *sql.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, c := range migrations {
+		exists, err := columnExists(ctx, db, c.table, c.name)
+		if err != nil {
+			panic("migrateDB: failed to inspect table - " + err.Error())
+		}
+		if exists {
+			continue
+		}
+
+		_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", c.table, c.name, c.def))
+		if err != nil {
+			panic("migrateDB: failed to add column - " + err.Error())
+		}
+	}
+
+	if err := migrateIdentityKeys(ctx, db); err != nil {
+		panic("migrateDB: failed to fill identity keys - " + err.Error())
+	}
+}
+
+func columnExists(ctx context.Context, db *sql.DB, table, name string) (bool, error) {
+	const slctQuery = `
+		SELECT EXISTS(SELECT 1 FROM pragma_table_info(?) WHERE name=?);
+	`
+	var exists bool
+	if err := db.QueryRowContext(ctx, slctQuery, table, name).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
 func (s *Storage) User(ctx context.Context, key interface{}) (models.User, error) {
 	switch key.(type) {
 	case string:
@@ -85,22 +283,15 @@ func (s *Storage) User(ctx context.Context, key interface{}) (models.User, error
 
 func (s *Storage) UserByLogin(ctx context.Context, login string) (models.User, error) {
 	const op = "sqlite.UserByLogin"
-	var user models.User
-
-	prep, err := s.db.PrepareContext(ctx, "SELECT uuid, login, email, passhash FROM users WHERE login=?;")
-	if err != nil {
-		return user, fmt.Errorf("%s: %w", op, err)
-	}
-	defer prep.Close()
-
-	row := prep.QueryRowContext(ctx, login)
+	const slctQuery = `
+		SELECT uuid, login, email, passhash, email_verified FROM users
+		WHERE login_key = ?1 OR (login_key IS NULL AND login = ?2)
+		ORDER BY login_key IS NULL DESC
+		LIMIT 1;
+	`
 
-	err = row.Scan(&user.UUID, &user.Login, &user.Email, &user.PassHash)
+	user, err := s.userByKey(ctx, slctQuery, login)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return user, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
-		}
-
 		return user, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -111,7 +302,7 @@ func (s *Storage) UserByUUID(ctx context.Context, uuid int) (models.User, error)
 	const op = "sqlite.UserByUUID"
 	var user models.User
 
-	prep, err := s.db.PrepareContext(ctx, "SELECT uuid, login, email, passhash FROM users WHERE uuid=?;")
+	prep, err := s.db.PrepareContext(ctx, "SELECT uuid, login, email, passhash, email_verified FROM users WHERE uuid=?;")
 	if err != nil {
 		return user, fmt.Errorf("%s: %w", op, err)
 	}
@@ -119,7 +310,7 @@ func (s *Storage) UserByUUID(ctx context.Context, uuid int) (models.User, error)
 
 	row := prep.QueryRowContext(ctx, uuid)
 
-	err = row.Scan(&user.UUID, &user.Login, &user.Email, &user.PassHash)
+	err = row.Scan(&user.UUID, &user.Login, &user.Email, &user.PassHash, &user.EmailVerified)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return user, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
@@ -131,12 +322,29 @@ func (s *Storage) UserByUUID(ctx context.Context, uuid int) (models.User, error)
 	return user, nil
 }
 
+func (s *Storage) UserByEmail(ctx context.Context, email string) (models.User, error) {
+	const op = "sqlite.UserByEmail"
+	const slctQuery = `
+		SELECT uuid, login, email, passhash, email_verified FROM users
+		WHERE email_key = ?1 OR (email_key IS NULL AND email = ?2)
+		ORDER BY email_key IS NULL DESC
+		LIMIT 1;
+	`
+
+	user, err := s.userByKey(ctx, slctQuery, email)
+	if err != nil {
+		return user, e.Fail(op, err)
+	}
+
+	return user, nil
+}
+
 func (s *Storage) Users(ctx context.Context, uuids []int) ([]models.User, error) {
 	const op = "sqlite.Users"
 
 	prep, err := s.db.PrepareContext(
 		ctx,
-		`SELECT uuid, login, email, passhash FROM users WHERE uuid IN (`+
+		`SELECT uuid, login, email, passhash, email_verified FROM users WHERE uuid IN (`+
 			strings.TrimSuffix(strings.Repeat("?,", len(uuids)), ",")+
 			`);`)
 	if err != nil {
@@ -156,7 +364,7 @@ func (s *Storage) Users(ctx context.Context, uuids []int) ([]models.User, error)
 	users := make([]models.User, 0)
 	var user models.User
 	for rows.Next() {
-		err = rows.Scan(&user.UUID, &user.Login, &user.Email, &user.PassHash)
+		err = rows.Scan(&user.UUID, &user.Login, &user.Email, &user.PassHash, &user.EmailVerified)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
@@ -173,7 +381,7 @@ func (s *Storage) Users(ctx context.Context, uuids []int) ([]models.User, error)
 func (s *Storage) UsersByLogin(ctx context.Context, login string) ([]models.User, error) {
 	const op = "sqlite.UsersByLogin"
 	const slctQuery = `
-		SELECT uuid, login, email 
+		SELECT uuid, login, email, email_verified
 		FROM users
 		WHERE login LIKE ?
 	`
@@ -196,13 +404,16 @@ func (s *Storage) Save(ctx context.Context, login, email string, passHash []byte
 	const op = "sqlite.Save"
 	uuid := uint64(0)
 
-	prep, err := s.db.PrepareContext(ctx, "INSERT INTO users(login, email, passhash) VALUES(?, ?, ?);")
+	prep, err := s.db.PrepareContext(
+		ctx,
+		"INSERT INTO users(login, email, passhash, login_key, email_key) VALUES(?, ?, ?, ?, ?);",
+	)
 	if err != nil {
 		return uuid, fmt.Errorf("%s: %w", op, err)
 	}
 	defer prep.Close()
 
-	res, err := prep.ExecContext(ctx, login, email, passHash)
+	res, err := prep.ExecContext(ctx, login, email, passHash, identity.Normalize(login), identity.Normalize(email))
 	if err != nil {
 		var sqlerr sqlite3.Error
 		if errors.As(err, &sqlerr) {
@@ -217,6 +428,15 @@ func (s *Storage) Save(ctx context.Context, login, email string, passHash []byte
 		return uuid, fmt.Errorf("%s: %w", op, err)
 	}
 
+	// the row is ignored if login or email is taken as another identifier
+	n, err := res.RowsAffected()
+	if err != nil {
+		return uuid, fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return uuid, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+	}
+
 	temp, err := res.LastInsertId()
 	if err != nil {
 		return uuid, fmt.Errorf("%s: %w", op, err)
@@ -226,6 +446,120 @@ func (s *Storage) Save(ctx context.Context, login, email string, passHash []byte
 	return uuid, nil
 }
 
+func (s *Storage) UpdatePassword(ctx context.Context, uuid uint64, passHash []byte) error {
+	const op = "sqlite.UpdatePassword"
+	const updtQuery = `
+		UPDATE users SET passhash=? WHERE uuid=?;
+	`
+
+	res, err := s.db.ExecContext(ctx, updtQuery, passHash, uuid)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	if n == 0 {
+		return e.Fail(op, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+// ReplacePassword sets new hash of the user password if the stored hash is
+// still oldHash. It reports whether the hash is replaced
+func (s *Storage) ReplacePassword(ctx context.Context, uuid uint64, oldHash, newHash []byte) (bool, error) {
+	const op = "sqlite.ReplacePassword"
+	const updtQuery = `
+		UPDATE users SET passhash=? WHERE uuid=? AND passhash=?;
+	`
+
+	n, err := s.execAffected(ctx, op, updtQuery, newHash, uuid, oldHash)
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// ReplacePasswordKeepingSession replaces hash of the user password like
+// ReplacePassword and revokes refresh tokens of the user sessions except the
+// kept one in the same transaction. It reports whether the hash is replaced and
+// number of revoked tokens
+func (s *Storage) ReplacePasswordKeepingSession(
+	ctx context.Context,
+	uuid uint64,
+	oldHash, newHash []byte,
+	keptSession string,
+) (bool, int64, error) {
+	const op = "sqlite.ReplacePasswordKeepingSession"
+	const updtQuery = `
+		UPDATE users SET passhash=? WHERE uuid=? AND passhash=?;
+	`
+	const rvkQuery = `
+		UPDATE tokens SET revoked = 1 WHERE user_id = ? AND session_id <> ? AND revoked = 0;
+	`
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, 0, e.Fail(op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, updtQuery, newHash, uuid, oldHash)
+	if err != nil {
+		return false, 0, e.Fail(op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, 0, e.Fail(op, err)
+	}
+	if n == 0 {
+		return false, 0, nil
+	}
+
+	res, err = tx.ExecContext(ctx, rvkQuery, uuid, keptSession)
+	if err != nil {
+		return false, 0, e.Fail(op, err)
+	}
+
+	revoked, err := res.RowsAffected()
+	if err != nil {
+		return false, 0, e.Fail(op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, 0, e.Fail(op, err)
+	}
+
+	return true, revoked, nil
+}
+
+func (s *Storage) SetEmailVerified(ctx context.Context, uuid uint64) error {
+	const op = "sqlite.SetEmailVerified"
+	const updtQuery = `
+		UPDATE users SET email_verified=1 WHERE uuid=?;
+	`
+
+	res, err := s.db.ExecContext(ctx, updtQuery, uuid)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return e.Fail(op, err)
+	}
+	if n == 0 {
+		return e.Fail(op, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
 func (s *Storage) Follow(
 	ctx context.Context,
 	src, target int,
@@ -267,7 +601,7 @@ func (s *Storage) Followers(
 ) ([]models.User, error) {
 	const op = "sqlite.Followers"
 	const insrtQuery = `
-		SELECT uuid, login, email
+		SELECT uuid, login, email, email_verified
 		FROM users
 		JOIN followings ON followings.follower = users.uuid
 		WHERE followee=$1
@@ -293,7 +627,7 @@ func (s *Storage) Followees(
 ) ([]models.User, error) {
 	const op = "sqlite.Followees"
 	const insrtQuery = `
-		SELECT uuid, login, email
+		SELECT uuid, login, email, email_verified
 		FROM users
 		JOIN followings ON followings.followee = users.uuid
 		WHERE follower=$1
@@ -313,56 +647,6 @@ func (s *Storage) Followees(
 	return users, nil
 }
 
-func (s *Storage) StoreToken(
-	ctx context.Context,
-	refreshToken, accessToken string,
-) error {
-	const op = "sqlite.StoreToken"
-	const insrtQuery = `
-		INSERT INTO tokens(refresh_token, access_token) VALUES(?, ?);
-	`
-	_, err := s.db.ExecContext(ctx, insrtQuery, refreshToken, accessToken)
-	if err != nil {
-		return e.Fail(op, err)
-	}
-
-	return nil
-}
-
-func (s *Storage) Token(
-	ctx context.Context,
-	refreshToken string,
-) (string, error) {
-	const op = "sqlite.StoreToken"
-	const insrtQuery = `
-		SELECT access_token FROM tokens WHERE refresh_token=?;
-	`
-	row := s.db.QueryRowContext(ctx, insrtQuery, refreshToken)
-	var token string
-	if err := row.Scan(&token); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", e.Fail(op, storage.ErrNotFound)
-		}
-
-		return "", e.Fail(op, err)
-	}
-
-	return token, nil
-}
-
-func (s *Storage) DeleteToken(ctx context.Context, refreshToken string) error {
-	const op = "sqlite.DeleteToken"
-	const deleteQuery = `
-		DELETE FROM tokens WHERE refresh_token = ?;
-	`
-	_, err := s.db.ExecContext(ctx, deleteQuery, refreshToken)
-	if err != nil {
-		return e.Fail(op, err)
-	}
-
-	return nil
-}
-
 func (s *Storage) scanUsers(rows *sql.Rows) ([]models.User, error) {
 	const op = "sqlite.scanFollowUsers"
 
@@ -370,7 +654,7 @@ func (s *Storage) scanUsers(rows *sql.Rows) ([]models.User, error) {
 	var user models.User
 	for rows.Next() {
 
-		if err := rows.Scan(&user.UUID, &user.Login, &user.Email); err != nil {
+		if err := rows.Scan(&user.UUID, &user.Login, &user.Email, &user.EmailVerified); err != nil {
 			return nil, e.Fail(op, err)
 		}
 
@@ -0,0 +1,185 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	e "Service/internal/lib/errors"
+)
+
+func (s *Storage) StoreToken(
+	ctx context.Context,
+	token models.TrackedToken,
+) error {
+	const op = "sqlite.StoreToken"
+	const insrtQuery = `
+		INSERT INTO tokens(
+			token_hash, user_id, session_id, issued_at, expires_at,
+			access_jti, access_expires_at, client_id, scope
+		)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	_, err := s.db.ExecContext(
+		ctx,
+		insrtQuery,
+		token.Hash,
+		token.UserID,
+		token.SessionID,
+		token.IssuedAt.Unix(),
+		token.ExpiresAt.Unix(),
+		token.Access.JTI,
+		token.Access.ExpiresAt.Unix(),
+		token.Grant.ClientID,
+		strings.Join(token.Grant.Scopes, " "),
+	)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) Token(
+	ctx context.Context,
+	hash []byte,
+) (models.TrackedToken, error) {
+	const op = "sqlite.Token"
+	const slctQuery = `
+		SELECT
+			token_hash, user_id, session_id, issued_at, expires_at, used, revoked,
+			client_id, scope
+		FROM tokens
+		WHERE token_hash=?;
+	`
+	row := s.db.QueryRowContext(ctx, slctQuery, hash)
+	var (
+		token     models.TrackedToken
+		issuedAt  int64
+		expiresAt int64
+		scope     string
+	)
+	err := row.Scan(
+		&token.Hash,
+		&token.UserID,
+		&token.SessionID,
+		&issuedAt,
+		&expiresAt,
+		&token.Used,
+		&token.Revoked,
+		&token.Grant.ClientID,
+		&scope,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TrackedToken{}, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return models.TrackedToken{}, e.Fail(op, err)
+	}
+
+	token.IssuedAt = time.Unix(issuedAt, 0)
+	token.ExpiresAt = time.Unix(expiresAt, 0)
+	token.Grant.Scopes = strings.Fields(scope)
+	return token, nil
+}
+
+// MarkTokenUsed marks refresh token as rotated. It returns false if the token
+// has been already used before
+func (s *Storage) MarkTokenUsed(ctx context.Context, hash []byte) (bool, error) {
+	const op = "sqlite.MarkTokenUsed"
+	const updtQuery = `
+		UPDATE tokens SET used = 1 WHERE token_hash = ? AND used = 0;
+	`
+	res, err := s.db.ExecContext(ctx, updtQuery, hash)
+	if err != nil {
+		return false, e.Fail(op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, e.Fail(op, err)
+	}
+
+	return n == 1, nil
+}
+
+// SessionActive reports whether the session has refresh token which is
+// neither revoked nor expired
+func (s *Storage) SessionActive(
+	ctx context.Context,
+	sessionID string,
+) (bool, error) {
+	const op = "sqlite.SessionActive"
+	const slctQuery = `
+		SELECT EXISTS(
+			SELECT 1 FROM tokens WHERE session_id=? AND revoked=0 AND expires_at>?
+		);
+	`
+	var active bool
+	err := s.db.QueryRowContext(ctx, slctQuery, sessionID, time.Now().Unix()).Scan(&active)
+	if err != nil {
+		return false, e.Fail(op, err)
+	}
+
+	return active, nil
+}
+
+func (s *Storage) RevokeSession(ctx context.Context, sessionID string) (int64, error) {
+	const op = "sqlite.RevokeSession"
+	const updtQuery = `
+		UPDATE tokens SET revoked = 1 WHERE session_id = ? AND revoked = 0;
+	`
+	return s.execAffected(ctx, op, updtQuery, sessionID)
+}
+
+func (s *Storage) RevokeUserTokens(ctx context.Context, uuid uint64) (int64, error) {
+	const op = "sqlite.RevokeUserTokens"
+	const updtQuery = `
+		UPDATE tokens SET revoked = 1 WHERE user_id = ? AND revoked = 0;
+	`
+	return s.execAffected(ctx, op, updtQuery, uuid)
+}
+
+func (s *Storage) RevokeUserSession(ctx context.Context, uuid uint64, sessionID string) (int64, error) {
+	const op = "sqlite.RevokeUserSession"
+	const updtQuery = `
+		UPDATE tokens SET revoked = 1 WHERE user_id = ? AND session_id = ? AND revoked = 0;
+	`
+	return s.execAffected(ctx, op, updtQuery, uuid, sessionID)
+}
+
+// PurgeTokens removes at most limit refresh tokens which are revoked or
+// expired before the time
+func (s *Storage) PurgeTokens(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int64, error) {
+	const op = "sqlite.PurgeTokens"
+	const dltQuery = `
+		DELETE FROM tokens WHERE id IN (
+			SELECT id FROM tokens WHERE revoked=1 OR expires_at<=? LIMIT ?
+		);
+	`
+	return s.execAffected(ctx, op, dltQuery, before.Unix(), limit)
+}
+
+// execAffected executes the query and returns number of affected rows
+func (s *Storage) execAffected(ctx context.Context, op, query string, args ...any) (int64, error) {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, e.Fail(op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, e.Fail(op, err)
+	}
+
+	return n, nil
+}
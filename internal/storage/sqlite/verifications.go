@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	e "Service/internal/lib/errors"
+)
+
+// SaveVerification saves email verification code of the user. Previous code
+// of the user is replaced
+func (s *Storage) SaveVerification(
+	ctx context.Context,
+	verification models.EmailVerification,
+) error {
+	const op = "sqlite.SaveVerification"
+	const insrtQuery = `
+		INSERT OR REPLACE INTO email_verifications(user_id, code_hash, sent_at, expires_at)
+		VALUES(?, ?, ?, ?);
+	`
+	_, err := s.db.ExecContext(
+		ctx,
+		insrtQuery,
+		verification.UserID,
+		verification.Hash,
+		verification.SentAt.Unix(),
+		verification.ExpiresAt.Unix(),
+	)
+	if err != nil {
+		return e.Fail(op, err)
+	}
+
+	return nil
+}
+
+// VerificationSentAt returns time the last verification code was sent to the
+// user at
+func (s *Storage) VerificationSentAt(ctx context.Context, uuid uint64) (time.Time, error) {
+	const op = "sqlite.VerificationSentAt"
+	const slctQuery = `
+		SELECT sent_at FROM email_verifications WHERE user_id=?;
+	`
+
+	var sentAt int64
+	if err := s.db.QueryRowContext(ctx, slctQuery, uuid).Scan(&sentAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return time.Time{}, e.Fail(op, err)
+	}
+
+	return time.Unix(sentAt, 0), nil
+}
+
+// UseVerification removes the verification code and returns uuid of its
+// owner. Expired codes are reported as not found
+func (s *Storage) UseVerification(
+	ctx context.Context,
+	hash []byte,
+	now time.Time,
+) (uint64, error) {
+	const op = "sqlite.UseVerification"
+	const dltQuery = `
+		DELETE FROM email_verifications
+		WHERE code_hash = ? AND expires_at > ?
+		RETURNING user_id;
+	`
+
+	var uuid uint64
+	if err := s.db.QueryRowContext(ctx, dltQuery, hash, now.Unix()).Scan(&uuid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, e.Fail(op, storage.ErrNotFound)
+		}
+
+		return 0, e.Fail(op, err)
+	}
+
+	return uuid, nil
+}
+
+// PurgeVerifications removes at most limit verification codes expired before
+// the time
+func (s *Storage) PurgeVerifications(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int64, error) {
+	const op = "sqlite.PurgeVerifications"
+	const dltQuery = `
+		DELETE FROM email_verifications WHERE user_id IN (
+			SELECT user_id FROM email_verifications WHERE expires_at<=? LIMIT ?
+		);
+	`
+	return s.execAffected(ctx, op, dltQuery, before.Unix(), limit)
+}
@@ -0,0 +1,469 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.30.2
+// source: follow.proto
+
+package v1
+
+import (
+	user "github.com/IlianBuh/SSO_Protobuf/gen/go/user"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FollowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Src           int32                  `protobuf:"varint,1,opt,name=src,proto3" json:"src,omitempty"`
+	Target        int32                  `protobuf:"varint,2,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowRequest) Reset() {
+	*x = FollowRequest{}
+	mi := &file_follow_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowRequest) ProtoMessage() {}
+
+func (x *FollowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_follow_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowRequest.ProtoReflect.Descriptor instead.
+func (*FollowRequest) Descriptor() ([]byte, []int) {
+	return file_follow_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FollowRequest) GetSrc() int32 {
+	if x != nil {
+		return x.Src
+	}
+	return 0
+}
+
+func (x *FollowRequest) GetTarget() int32 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+type FollowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowResponse) Reset() {
+	*x = FollowResponse{}
+	mi := &file_follow_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowResponse) ProtoMessage() {}
+
+func (x *FollowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_follow_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowResponse.ProtoReflect.Descriptor instead.
+func (*FollowResponse) Descriptor() ([]byte, []int) {
+	return file_follow_proto_rawDescGZIP(), []int{1}
+}
+
+type UnfollowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Src           int32                  `protobuf:"varint,1,opt,name=src,proto3" json:"src,omitempty"`
+	Target        int32                  `protobuf:"varint,2,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnfollowRequest) Reset() {
+	*x = UnfollowRequest{}
+	mi := &file_follow_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnfollowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfollowRequest) ProtoMessage() {}
+
+func (x *UnfollowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_follow_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnfollowRequest.ProtoReflect.Descriptor instead.
+func (*UnfollowRequest) Descriptor() ([]byte, []int) {
+	return file_follow_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UnfollowRequest) GetSrc() int32 {
+	if x != nil {
+		return x.Src
+	}
+	return 0
+}
+
+func (x *UnfollowRequest) GetTarget() int32 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+type UnfollowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnfollowResponse) Reset() {
+	*x = UnfollowResponse{}
+	mi := &file_follow_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnfollowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfollowResponse) ProtoMessage() {}
+
+func (x *UnfollowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_follow_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnfollowResponse.ProtoReflect.Descriptor instead.
+func (*UnfollowResponse) Descriptor() ([]byte, []int) {
+	return file_follow_proto_rawDescGZIP(), []int{3}
+}
+
+type FollowersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          int32                  `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowersRequest) Reset() {
+	*x = FollowersRequest{}
+	mi := &file_follow_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowersRequest) ProtoMessage() {}
+
+func (x *FollowersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_follow_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowersRequest.ProtoReflect.Descriptor instead.
+func (*FollowersRequest) Descriptor() ([]byte, []int) {
+	return file_follow_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FollowersRequest) GetUuid() int32 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+type FollowersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          []*user.User           `protobuf:"bytes,1,rep,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowersResponse) Reset() {
+	*x = FollowersResponse{}
+	mi := &file_follow_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowersResponse) ProtoMessage() {}
+
+func (x *FollowersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_follow_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowersResponse.ProtoReflect.Descriptor instead.
+func (*FollowersResponse) Descriptor() ([]byte, []int) {
+	return file_follow_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FollowersResponse) GetUser() []*user.User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type FolloweesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          int32                  `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FolloweesRequest) Reset() {
+	*x = FolloweesRequest{}
+	mi := &file_follow_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolloweesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolloweesRequest) ProtoMessage() {}
+
+func (x *FolloweesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_follow_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolloweesRequest.ProtoReflect.Descriptor instead.
+func (*FolloweesRequest) Descriptor() ([]byte, []int) {
+	return file_follow_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FolloweesRequest) GetUuid() int32 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+type FolloweesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          []*user.User           `protobuf:"bytes,1,rep,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FolloweesResponse) Reset() {
+	*x = FolloweesResponse{}
+	mi := &file_follow_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FolloweesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FolloweesResponse) ProtoMessage() {}
+
+func (x *FolloweesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_follow_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FolloweesResponse.ProtoReflect.Descriptor instead.
+func (*FolloweesResponse) Descriptor() ([]byte, []int) {
+	return file_follow_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FolloweesResponse) GetUser() []*user.User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+var File_follow_proto protoreflect.FileDescriptor
+
+const file_follow_proto_rawDesc = "" +
+	"\n" +
+	"\ffollow.proto\x12\x06follow\x1a\n" +
+	"user.proto\"9\n" +
+	"\rFollowRequest\x12\x10\n" +
+	"\x03src\x18\x01 \x01(\x05R\x03src\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\x05R\x06target\"\x10\n" +
+	"\x0eFollowResponse\";\n" +
+	"\x0fUnfollowRequest\x12\x10\n" +
+	"\x03src\x18\x01 \x01(\x05R\x03src\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\x05R\x06target\"\x12\n" +
+	"\x10UnfollowResponse\"&\n" +
+	"\x10FollowersRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x05R\x04uuid\".\n" +
+	"\x11FollowersResponse\x12\x19\n" +
+	"\x04user\x18\x01 \x03(\v2\x05.UserR\x04user\"&\n" +
+	"\x10FolloweesRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x05R\x04uuid\".\n" +
+	"\x11FolloweesResponse\x12\x19\n" +
+	"\x04user\x18\x01 \x03(\v2\x05.UserR\x04user2\x84\x02\n" +
+	"\x06Follow\x127\n" +
+	"\x06Follow\x12\x15.follow.FollowRequest\x1a\x16.follow.FollowResponse\x12=\n" +
+	"\bUnfollow\x12\x17.follow.UnfollowRequest\x1a\x18.follow.UnfollowResponse\x12@\n" +
+	"\tFollowers\x12\x18.follow.FollowersRequest\x1a\x19.follow.FollowersResponse\x12@\n" +
+	"\tFollowees\x12\x18.follow.FolloweesRequest\x1a\x19.follow.FolloweesResponseB\x17Z\x15IlianBuh.follow.v1;v1b\x06proto3"
+
+var (
+	file_follow_proto_rawDescOnce sync.Once
+	file_follow_proto_rawDescData []byte
+)
+
+func file_follow_proto_rawDescGZIP() []byte {
+	file_follow_proto_rawDescOnce.Do(func() {
+		file_follow_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_follow_proto_rawDesc), len(file_follow_proto_rawDesc)))
+	})
+	return file_follow_proto_rawDescData
+}
+
+var file_follow_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_follow_proto_goTypes = []any{
+	(*FollowRequest)(nil),     // 0: follow.FollowRequest
+	(*FollowResponse)(nil),    // 1: follow.FollowResponse
+	(*UnfollowRequest)(nil),   // 2: follow.UnfollowRequest
+	(*UnfollowResponse)(nil),  // 3: follow.UnfollowResponse
+	(*FollowersRequest)(nil),  // 4: follow.FollowersRequest
+	(*FollowersResponse)(nil), // 5: follow.FollowersResponse
+	(*FolloweesRequest)(nil),  // 6: follow.FolloweesRequest
+	(*FolloweesResponse)(nil), // 7: follow.FolloweesResponse
+	(*user.User)(nil),         // 8: User
+}
+var file_follow_proto_depIdxs = []int32{
+	8, // 0: follow.FollowersResponse.user:type_name -> User
+	8, // 1: follow.FolloweesResponse.user:type_name -> User
+	0, // 2: follow.Follow.Follow:input_type -> follow.FollowRequest
+	2, // 3: follow.Follow.Unfollow:input_type -> follow.UnfollowRequest
+	4, // 4: follow.Follow.Followers:input_type -> follow.FollowersRequest
+	6, // 5: follow.Follow.Followees:input_type -> follow.FolloweesRequest
+	1, // 6: follow.Follow.Follow:output_type -> follow.FollowResponse
+	3, // 7: follow.Follow.Unfollow:output_type -> follow.UnfollowResponse
+	5, // 8: follow.Follow.Followers:output_type -> follow.FollowersResponse
+	7, // 9: follow.Follow.Followees:output_type -> follow.FolloweesResponse
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_follow_proto_init() }
+func file_follow_proto_init() {
+	if File_follow_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_follow_proto_rawDesc), len(file_follow_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_follow_proto_goTypes,
+		DependencyIndexes: file_follow_proto_depIdxs,
+		MessageInfos:      file_follow_proto_msgTypes,
+	}.Build()
+	File_follow_proto = out.File
+	file_follow_proto_goTypes = nil
+	file_follow_proto_depIdxs = nil
+}
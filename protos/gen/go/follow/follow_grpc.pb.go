@@ -0,0 +1,235 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.30.2
+// source: follow.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Follow_Follow_FullMethodName    = "/follow.Follow/Follow"
+	Follow_Unfollow_FullMethodName  = "/follow.Follow/Unfollow"
+	Follow_Followers_FullMethodName = "/follow.Follow/Followers"
+	Follow_Followees_FullMethodName = "/follow.Follow/Followees"
+)
+
+// FollowClient is the client API for Follow service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FollowClient interface {
+	Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*FollowResponse, error)
+	Unfollow(ctx context.Context, in *UnfollowRequest, opts ...grpc.CallOption) (*UnfollowResponse, error)
+	Followers(ctx context.Context, in *FollowersRequest, opts ...grpc.CallOption) (*FollowersResponse, error)
+	Followees(ctx context.Context, in *FolloweesRequest, opts ...grpc.CallOption) (*FolloweesResponse, error)
+}
+
+type followClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFollowClient(cc grpc.ClientConnInterface) FollowClient {
+	return &followClient{cc}
+}
+
+func (c *followClient) Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*FollowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FollowResponse)
+	err := c.cc.Invoke(ctx, Follow_Follow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followClient) Unfollow(ctx context.Context, in *UnfollowRequest, opts ...grpc.CallOption) (*UnfollowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnfollowResponse)
+	err := c.cc.Invoke(ctx, Follow_Unfollow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followClient) Followers(ctx context.Context, in *FollowersRequest, opts ...grpc.CallOption) (*FollowersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FollowersResponse)
+	err := c.cc.Invoke(ctx, Follow_Followers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *followClient) Followees(ctx context.Context, in *FolloweesRequest, opts ...grpc.CallOption) (*FolloweesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FolloweesResponse)
+	err := c.cc.Invoke(ctx, Follow_Followees_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FollowServer is the server API for Follow service.
+// All implementations must embed UnimplementedFollowServer
+// for forward compatibility.
+type FollowServer interface {
+	Follow(context.Context, *FollowRequest) (*FollowResponse, error)
+	Unfollow(context.Context, *UnfollowRequest) (*UnfollowResponse, error)
+	Followers(context.Context, *FollowersRequest) (*FollowersResponse, error)
+	Followees(context.Context, *FolloweesRequest) (*FolloweesResponse, error)
+	mustEmbedUnimplementedFollowServer()
+}
+
+// UnimplementedFollowServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFollowServer struct{}
+
+func (UnimplementedFollowServer) Follow(context.Context, *FollowRequest) (*FollowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Follow not implemented")
+}
+func (UnimplementedFollowServer) Unfollow(context.Context, *UnfollowRequest) (*UnfollowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unfollow not implemented")
+}
+func (UnimplementedFollowServer) Followers(context.Context, *FollowersRequest) (*FollowersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Followers not implemented")
+}
+func (UnimplementedFollowServer) Followees(context.Context, *FolloweesRequest) (*FolloweesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Followees not implemented")
+}
+func (UnimplementedFollowServer) mustEmbedUnimplementedFollowServer() {}
+func (UnimplementedFollowServer) testEmbeddedByValue()                {}
+
+// UnsafeFollowServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FollowServer will
+// result in compilation errors.
+type UnsafeFollowServer interface {
+	mustEmbedUnimplementedFollowServer()
+}
+
+func RegisterFollowServer(s grpc.ServiceRegistrar, srv FollowServer) {
+	// If the following call pancis, it indicates UnimplementedFollowServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Follow_ServiceDesc, srv)
+}
+
+func _Follow_Follow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServer).Follow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Follow_Follow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServer).Follow(ctx, req.(*FollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Follow_Unfollow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnfollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServer).Unfollow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Follow_Unfollow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServer).Unfollow(ctx, req.(*UnfollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Follow_Followers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServer).Followers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Follow_Followers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServer).Followers(ctx, req.(*FollowersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Follow_Followees_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FolloweesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowServer).Followees(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Follow_Followees_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowServer).Followees(ctx, req.(*FolloweesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Follow_ServiceDesc is the grpc.ServiceDesc for Follow service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Follow_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "follow.Follow",
+	HandlerType: (*FollowServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Follow",
+			Handler:    _Follow_Follow_Handler,
+		},
+		{
+			MethodName: "Unfollow",
+			Handler:    _Follow_Unfollow_Handler,
+		},
+		{
+			MethodName: "Followers",
+			Handler:    _Follow_Followers_Handler,
+		},
+		{
+			MethodName: "Followees",
+			Handler:    _Follow_Followees_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "follow.proto",
+}
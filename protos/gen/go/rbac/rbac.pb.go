@@ -0,0 +1,379 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.30.2
+// source: rbac.proto
+
+package rbacv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AssignRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          int32                  `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignRoleRequest) Reset() {
+	*x = AssignRoleRequest{}
+	mi := &file_rbac_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignRoleRequest) ProtoMessage() {}
+
+func (x *AssignRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignRoleRequest.ProtoReflect.Descriptor instead.
+func (*AssignRoleRequest) Descriptor() ([]byte, []int) {
+	return file_rbac_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AssignRoleRequest) GetUuid() int32 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+func (x *AssignRoleRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type AssignRoleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignRoleResponse) Reset() {
+	*x = AssignRoleResponse{}
+	mi := &file_rbac_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignRoleResponse) ProtoMessage() {}
+
+func (x *AssignRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignRoleResponse.ProtoReflect.Descriptor instead.
+func (*AssignRoleResponse) Descriptor() ([]byte, []int) {
+	return file_rbac_proto_rawDescGZIP(), []int{1}
+}
+
+type RevokeRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          int32                  `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeRoleRequest) Reset() {
+	*x = RevokeRoleRequest{}
+	mi := &file_rbac_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeRoleRequest) ProtoMessage() {}
+
+func (x *RevokeRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeRoleRequest.ProtoReflect.Descriptor instead.
+func (*RevokeRoleRequest) Descriptor() ([]byte, []int) {
+	return file_rbac_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RevokeRoleRequest) GetUuid() int32 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+func (x *RevokeRoleRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type RevokeRoleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeRoleResponse) Reset() {
+	*x = RevokeRoleResponse{}
+	mi := &file_rbac_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeRoleResponse) ProtoMessage() {}
+
+func (x *RevokeRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeRoleResponse.ProtoReflect.Descriptor instead.
+func (*RevokeRoleResponse) Descriptor() ([]byte, []int) {
+	return file_rbac_proto_rawDescGZIP(), []int{3}
+}
+
+type RolesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          int32                  `protobuf:"varint,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RolesRequest) Reset() {
+	*x = RolesRequest{}
+	mi := &file_rbac_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolesRequest) ProtoMessage() {}
+
+func (x *RolesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolesRequest.ProtoReflect.Descriptor instead.
+func (*RolesRequest) Descriptor() ([]byte, []int) {
+	return file_rbac_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RolesRequest) GetUuid() int32 {
+	if x != nil {
+		return x.Uuid
+	}
+	return 0
+}
+
+type RolesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roles         []string               `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`
+	Permissions   []string               `protobuf:"bytes,2,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RolesResponse) Reset() {
+	*x = RolesResponse{}
+	mi := &file_rbac_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolesResponse) ProtoMessage() {}
+
+func (x *RolesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rbac_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolesResponse.ProtoReflect.Descriptor instead.
+func (*RolesResponse) Descriptor() ([]byte, []int) {
+	return file_rbac_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RolesResponse) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *RolesResponse) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+var File_rbac_proto protoreflect.FileDescriptor
+
+const file_rbac_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"rbac.proto\x12\x04rbac\";\n" +
+	"\x11AssignRoleRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x05R\x04uuid\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\"\x14\n" +
+	"\x12AssignRoleResponse\";\n" +
+	"\x11RevokeRoleRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x05R\x04uuid\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\"\x14\n" +
+	"\x12RevokeRoleResponse\"\"\n" +
+	"\fRolesRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\x05R\x04uuid\"G\n" +
+	"\rRolesResponse\x12\x14\n" +
+	"\x05roles\x18\x01 \x03(\tR\x05roles\x12 \n" +
+	"\vpermissions\x18\x02 \x03(\tR\vpermissions2\xba\x01\n" +
+	"\x04RBAC\x12?\n" +
+	"\n" +
+	"AssignRole\x12\x17.rbac.AssignRoleRequest\x1a\x18.rbac.AssignRoleResponse\x12?\n" +
+	"\n" +
+	"RevokeRole\x12\x17.rbac.RevokeRoleRequest\x1a\x18.rbac.RevokeRoleResponse\x120\n" +
+	"\x05Roles\x12\x12.rbac.RolesRequest\x1a\x13.rbac.RolesResponseB\x19Z\x17IlianBuh.rbac.v1;rbacv1b\x06proto3"
+
+var (
+	file_rbac_proto_rawDescOnce sync.Once
+	file_rbac_proto_rawDescData []byte
+)
+
+func file_rbac_proto_rawDescGZIP() []byte {
+	file_rbac_proto_rawDescOnce.Do(func() {
+		file_rbac_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rbac_proto_rawDesc), len(file_rbac_proto_rawDesc)))
+	})
+	return file_rbac_proto_rawDescData
+}
+
+var file_rbac_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_rbac_proto_goTypes = []any{
+	(*AssignRoleRequest)(nil),  // 0: rbac.AssignRoleRequest
+	(*AssignRoleResponse)(nil), // 1: rbac.AssignRoleResponse
+	(*RevokeRoleRequest)(nil),  // 2: rbac.RevokeRoleRequest
+	(*RevokeRoleResponse)(nil), // 3: rbac.RevokeRoleResponse
+	(*RolesRequest)(nil),       // 4: rbac.RolesRequest
+	(*RolesResponse)(nil),      // 5: rbac.RolesResponse
+}
+var file_rbac_proto_depIdxs = []int32{
+	0, // 0: rbac.RBAC.AssignRole:input_type -> rbac.AssignRoleRequest
+	2, // 1: rbac.RBAC.RevokeRole:input_type -> rbac.RevokeRoleRequest
+	4, // 2: rbac.RBAC.Roles:input_type -> rbac.RolesRequest
+	1, // 3: rbac.RBAC.AssignRole:output_type -> rbac.AssignRoleResponse
+	3, // 4: rbac.RBAC.RevokeRole:output_type -> rbac.RevokeRoleResponse
+	5, // 5: rbac.RBAC.Roles:output_type -> rbac.RolesResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rbac_proto_init() }
+func file_rbac_proto_init() {
+	if File_rbac_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rbac_proto_rawDesc), len(file_rbac_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rbac_proto_goTypes,
+		DependencyIndexes: file_rbac_proto_depIdxs,
+		MessageInfos:      file_rbac_proto_msgTypes,
+	}.Build()
+	File_rbac_proto = out.File
+	file_rbac_proto_goTypes = nil
+	file_rbac_proto_depIdxs = nil
+}
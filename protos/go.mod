@@ -0,0 +1,3 @@
+module github.com/IlianBuh/SSO_Protobuf
+
+go 1.24
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/tests/suite"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestChangePassword(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	oldPass := randomFakePassword()
+	newPass := randomFakePassword()
+
+	current, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: oldPass,
+	})
+	require.NoError(t, err)
+
+	other, err := st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: oldPass,
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.ChangePassword(ctx, &authv1.ChangePasswordRequest{
+		AccessToken: current.GetAccessToken(),
+		OldPassword: oldPass,
+		NewPassword: newPass,
+	})
+	require.NoError(t, err)
+
+	introspection, err := st.Introspect(ctx, current.GetAccessToken())
+	require.NoError(t, err)
+	assert.True(t, introspection.GetActive(), "current session is kept")
+
+	_, err = st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{
+		RefreshToken: current.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+
+	introspection, err = st.Introspect(ctx, other.GetAccessToken())
+	require.NoError(t, err)
+	assert.False(t, introspection.GetActive(), "other session is revoked")
+
+	_, err = st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{
+		RefreshToken: other.GetRefreshToken(),
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: oldPass,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: newPass,
+	})
+	require.NoError(t, err)
+}
+
+func TestChangePasswordWrongOldPassword(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	resp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName(),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.ChangePassword(ctx, &authv1.ChangePasswordRequest{
+		AccessToken: resp.GetAccessToken(),
+		OldPassword: randomFakePassword(),
+		NewPassword: randomFakePassword(),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = st.Client.ChangePassword(ctx, &authv1.ChangePasswordRequest{
+		AccessToken: "not a token",
+		OldPassword: randomFakePassword(),
+		NewPassword: randomFakePassword(),
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
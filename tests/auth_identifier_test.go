@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/domain/models"
+	"Service/internal/storage"
+	"Service/internal/storage/sqlite"
+	"Service/tests/suite"
+	"strings"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginByIdentifier(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName() + "X"
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	for _, identifier := range []string{
+		login,
+		strings.ToLower(login),
+		"  " + strings.ToUpper(login) + " ",
+		email,
+		strings.ToUpper(email),
+	} {
+		_, err = st.Client.Login(ctx, &authv1.LoginRequest{
+			Login:    identifier,
+			Password: pass,
+		})
+		assert.NoError(t, err, "failed to log in as %q", identifier)
+	}
+}
+
+func TestSignUpCaseInsensitiveUniqueness(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName() + "Y"
+	email := gofakeit.Email()
+
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    strings.ToUpper(login),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	assert.Error(t, err, "login differing in case is accepted")
+
+	_, err = st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName() + "Z",
+		Email:    strings.ToUpper(email),
+		Password: randomFakePassword(),
+	})
+	assert.Error(t, err, "email differing in case is accepted")
+}
+
+func TestSignUpLoginLooksLikeEmail(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.Email(),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	for _, at := range []string{"\uFF20", "\uFE6B"} {
+		_, err = st.Client.SignUp(ctx, &authv1.SignUpRequest{
+			Login:    gofakeit.FirstName() + at + gofakeit.DomainName(),
+			Email:    gofakeit.Email(),
+			Password: randomFakePassword(),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "login normalized to email is accepted")
+	}
+}
+
+func TestLoginAndEmailKeysAreMutuallyUnique(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	// imported emails are not validated, so they may look like logins
+	mailbox := gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word()
+	skipped, err := db.ImportUsers(ctx, []models.User{{
+		Login:    gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word(),
+		Email:    mailbox,
+		PassHash: []byte("$scrypt$ln=10,r=8,p=1$c2FsdA$a2V5"),
+	}})
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+
+	_, err = st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    strings.ToUpper(mailbox),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	assert.Error(t, err, "login equal to email of another user is accepted")
+
+	login := gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word()
+	_, err = st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = db.Save(ctx, gofakeit.FirstName()+gofakeit.LastName()+gofakeit.Word(), strings.ToLower(login), []byte("hash"))
+	assert.ErrorIs(t, err, storage.ErrUserExists, "email equal to login of another user is accepted")
+
+	skipped, err = db.ImportUsers(ctx, []models.User{{
+		Login:    gofakeit.FirstName() + "\uFF20" + gofakeit.DomainName(),
+		Email:    gofakeit.Email(),
+		PassHash: []byte("$scrypt$ln=10,r=8,p=1$c2FsdA$a2V5"),
+	}})
+	require.NoError(t, err)
+	assert.Len(t, skipped, 1, "login normalized to email is imported")
+}
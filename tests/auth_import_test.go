@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/domain/models"
+	"Service/internal/storage/sqlite"
+	"Service/tests/suite"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/scrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginImportedUsers(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	tt := []struct {
+		name string
+		hash func(pass string) string
+	}{
+		{
+			name: "pbkdf2-sha256",
+			hash: func(pass string) string {
+				salt := rand.Text()
+				key, err := pbkdf2.Key(sha256.New, pass, []byte(salt), 1000, 32)
+				require.NoError(t, err)
+				return fmt.Sprintf("$pbkdf2-sha256$1000$%s$%s", b64(salt), b64(string(key)))
+			},
+		},
+		{
+			name: "scrypt",
+			hash: func(pass string) string {
+				salt := rand.Text()
+				key, err := scrypt.Key([]byte(pass), []byte(salt), 1<<10, 8, 1, 32)
+				require.NoError(t, err)
+				return fmt.Sprintf("$scrypt$ln=10,r=8,p=1$%s$%s", b64(salt), b64(string(key)))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			user := models.User{
+				Login: gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word(),
+				Email: gofakeit.Email(),
+			}
+			pass := randomFakePassword()
+			user.PassHash = []byte(tc.hash(pass))
+
+			skipped, err := db.ImportUsers(ctx, []models.User{user})
+			require.NoError(t, err)
+			require.Empty(t, skipped)
+
+			_, err = st.Client.Login(ctx, &authv1.LoginRequest{Login: user.Login, Password: randomFakePassword()})
+			require.Equal(t, codes.InvalidArgument, status.Code(err), "wrong password is accepted")
+
+			_, err = st.Client.Login(ctx, &authv1.LoginRequest{Login: user.Login, Password: pass})
+			require.NoError(t, err)
+
+			stored, err := db.User(ctx, user.Login)
+			require.NoError(t, err)
+			assert.True(
+				t,
+				strings.HasPrefix(string(stored.PassHash), currentArgon2idPrefix(cfg)),
+				"password is not rehashed: %q", stored.PassHash,
+			)
+		})
+	}
+}
+
+func TestImportSkipsExistingUsers(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	login := gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word()
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	fresh := models.User{
+		Login:    gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word(),
+		Email:    gofakeit.Email(),
+		PassHash: []byte("$scrypt$ln=10,r=8,p=1$c2FsdA$a2V5"),
+	}
+	skipped, err := db.ImportUsers(ctx, []models.User{
+		{Login: login, Email: gofakeit.Email(), PassHash: fresh.PassHash},
+		fresh,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{login}, skipped)
+
+	_, err = db.User(ctx, fresh.Login)
+	assert.NoError(t, err, "user after existing one is not imported")
+}
+
+func b64(s string) string {
+	return base64.RawStdEncoding.EncodeToString([]byte(s))
+}
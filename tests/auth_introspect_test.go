@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/tests/suite"
+	"testing"
+	"time"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIntrospectActiveToken(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	signUpTime := time.Now()
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	resp, err := st.Introspect(ctx, respSignUp.GetAccessToken())
+	require.NoError(t, err)
+
+	assert.True(t, resp.GetActive())
+	assert.NotZero(t, resp.GetUuid())
+	assert.Equal(t, login, resp.GetLogin())
+	assert.InDelta(t, signUpTime.Add(st.Cfg.TokenTTL).Unix(), resp.GetExp(), 1)
+}
+
+func TestIntrospectInvalidToken(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	resp, err := st.Introspect(ctx, "definitely.not.token")
+	require.NoError(t, err)
+
+	assert.False(t, resp.GetActive())
+	assert.Zero(t, resp.GetUuid())
+	assert.Empty(t, resp.GetLogin())
+}
+
+func TestIntrospectIsInternal(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word(),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+	req := &authv1.IntrospectRequest{Token: respSignUp.GetAccessToken()}
+
+	_, err = st.Client.Introspect(ctx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "anonymous introspection")
+
+	_, err = st.Client.Introspect(suite.WithToken(ctx, respSignUp.GetAccessToken()), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "introspection by user")
+
+	unscoped, err := st.ClientToken(suite.IntrospectClientID, "users:read")
+	require.NoError(t, err)
+	_, err = st.Client.Introspect(suite.WithToken(ctx, unscoped), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "introspection without scope")
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/tests/suite"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginLockout(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	pass := randomFakePassword()
+
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	for range cfg.Lockout.Login.Threshold {
+		_, err = st.Client.Login(ctx, &authv1.LoginRequest{
+			Login:    login,
+			Password: randomFakePassword(),
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err = st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.Error(t, err)
+
+	s := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, s.Code(), "correct password is rejected while locked")
+
+	var retry *errdetails.RetryInfo
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			retry = info
+		}
+	}
+	require.NotNil(t, retry, "retry info is not attached")
+	assert.Positive(t, retry.GetRetryDelay().AsDuration())
+	assert.LessOrEqual(t, retry.GetRetryDelay().AsDuration(), cfg.Lockout.Login.BaseDelay)
+}
+
+func TestLoginLockoutResetOnSuccess(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	pass := randomFakePassword()
+
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		for range cfg.Lockout.Login.Threshold - 1 {
+			_, err = st.Client.Login(ctx, &authv1.LoginRequest{
+				Login:    login,
+				Password: randomFakePassword(),
+			})
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		}
+
+		_, err = st.Client.Login(ctx, &authv1.LoginRequest{
+			Login:    login,
+			Password: pass,
+		})
+		require.NoError(t, err)
+	}
+}
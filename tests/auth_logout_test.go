@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/tests/suite"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogout(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName(),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.Logout(ctx, &authv1.LogoutRequest{
+		RefreshToken: respSignUp.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+
+	introspection, err := st.Introspect(ctx, respSignUp.GetAccessToken())
+	require.NoError(t, err)
+	assert.False(t, introspection.GetActive())
+
+	_, err = st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{
+		RefreshToken: respSignUp.GetRefreshToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestLogoutAll(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	pass := randomFakePassword()
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	introspection, err := st.Introspect(ctx, respLogin.GetAccessToken())
+	require.NoError(t, err)
+	require.True(t, introspection.GetActive())
+
+	_, err = st.Client.LogoutAll(suite.WithToken(ctx, respLogin.GetAccessToken()), &authv1.LogoutAllRequest{
+		Uuid: introspection.GetUuid(),
+	})
+	require.NoError(t, err)
+
+	for _, token := range []string{respSignUp.GetAccessToken(), respLogin.GetAccessToken()} {
+		introspection, err = st.Introspect(ctx, token)
+		require.NoError(t, err)
+		assert.False(t, introspection.GetActive())
+	}
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/lib/password"
+	"Service/internal/storage/sqlite"
+	"Service/tests/suite"
+	"fmt"
+	"strings"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSignUpHashesWithArgon2id(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	login := gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word()
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	user, err := db.User(ctx, login)
+	require.NoError(t, err)
+	assert.True(
+		t,
+		strings.HasPrefix(string(user.PassHash), currentArgon2idPrefix(cfg)),
+		"unexpected hash %q", user.PassHash,
+	)
+}
+
+func TestLoginRehashesOutdatedPassword(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	outdated, err := password.NewHasher(password.Argon2idParams{
+		Memory:      8,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name string
+		hash func(pass string) ([]byte, error)
+	}{
+		{
+			name: "bcrypt",
+			hash: func(pass string) ([]byte, error) {
+				return bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+			},
+		},
+		{
+			name: "argon2id with outdated parameters",
+			hash: outdated.Hash,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			login := gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word()
+			pass := randomFakePassword()
+
+			hash, err := tc.hash(pass)
+			require.NoError(t, err)
+			_, err = db.Save(ctx, login, gofakeit.Email(), hash)
+			require.NoError(t, err)
+
+			_, err = st.Client.Login(ctx, &authv1.LoginRequest{Login: login, Password: pass})
+			require.NoError(t, err)
+
+			user, err := db.User(ctx, login)
+			require.NoError(t, err)
+			assert.True(
+				t,
+				strings.HasPrefix(string(user.PassHash), currentArgon2idPrefix(cfg)),
+				"password is not rehashed: %q", user.PassHash,
+			)
+
+			_, err = st.Client.Login(ctx, &authv1.LoginRequest{Login: login, Password: pass})
+			require.NoError(t, err, "rehashed password is not accepted")
+		})
+	}
+}
+
+func currentArgon2idPrefix(cfg *config.Config) string {
+	p := cfg.Password.Argon2id
+	return fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$", p.Memory, p.Iterations, p.Parallelism)
+}
+
+func TestVerifyRejectsUnboundedParameters(t *testing.T) {
+	hasher, err := password.NewHasher(password.Argon2idParams{
+		Memory:      8,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+	require.NoError(t, err)
+
+	const (
+		salt = "c2FsdHNhbHRzYWx0c2FsdA"
+		key  = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	)
+
+	tt := []struct {
+		name string
+		hash string
+	}{
+		{name: "argon2id memory", hash: "$argon2id$v=19$m=4194304,t=1,p=1$" + salt + "$" + key},
+		{name: "argon2id iterations", hash: "$argon2id$v=19$m=19456,t=100000,p=1$" + salt + "$" + key},
+		{name: "argon2id parallelism", hash: "$argon2id$v=19$m=19456,t=1,p=255$" + salt + "$" + key},
+		{name: "scrypt cost", hash: "$scrypt$ln=30,r=8,p=1$" + salt + "$" + key},
+		{name: "scrypt block size", hash: "$scrypt$ln=14,r=1000000,p=1$" + salt + "$" + key},
+		{name: "scrypt parallelism", hash: "$scrypt$ln=14,r=8,p=1000000$" + salt + "$" + key},
+		{name: "pbkdf2 rounds", hash: "$pbkdf2-sha256$2000000000$" + salt + "$" + key},
+		{name: "pbkdf2 phc rounds", hash: "$pbkdf2-sha256$i=2000000000,l=32$" + salt + "$" + key},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := hasher.Verify([]byte(tc.hash), "password")
+			assert.ErrorIs(t, err, password.ErrMalformedHash)
+		})
+	}
+
+	_, err = password.NewHasher(password.Argon2idParams{
+		Memory:      4 << 20,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+	assert.Error(t, err, "hasher accepts parameters verification rejects")
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/domain/models"
+	"Service/internal/services/passpolicy"
+	"Service/internal/storage/sqlite"
+	"Service/tests/suite"
+	"strings"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSignUpPasswordPolicy(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+
+	tt := []struct {
+		name     string
+		password func(login string) string
+		rule     string
+	}{
+		{
+			name:     "too short",
+			password: func(string) string { return "a1-b2" },
+			rule:     passpolicy.RuleTooShort,
+		},
+		{
+			name: "too long",
+			password: func(string) string {
+				return strings.Repeat("x", cfg.Password.Policy.MaxLength+1)
+			},
+			rule: passpolicy.RuleTooLong,
+		},
+		{
+			name:     "contains login",
+			password: func(login string) string { return "my-" + strings.ToUpper(login) + "-pass" },
+			rule:     passpolicy.RuleContainsIdentity,
+		},
+		{
+			name:     "breached",
+			password: func(string) string { return "Password123" },
+			rule:     passpolicy.RuleBreached,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			login := gofakeit.FirstName() + gofakeit.LastName()
+
+			_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+				Login:    login,
+				Email:    gofakeit.Email(),
+				Password: tc.password(login),
+			})
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Contains(t, violatedRules(t, err, "password"), tc.rule)
+		})
+	}
+}
+
+func TestLoginWithLegacyShortPassword(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	login := gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word()
+	pass := "k3y!"
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+	require.NoError(t, err)
+	_, err = db.Save(ctx, login, gofakeit.Email(), hash)
+	require.NoError(t, err)
+
+	_, err = st.Client.Login(ctx, &authv1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err, "policy must not be applied at login")
+}
+
+func TestPasswordResetPolicyKeepsToken(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	email := gofakeit.Email()
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName(),
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.RequestPasswordReset(ctx, &authv1.RequestPasswordResetRequest{Email: email})
+	require.NoError(t, err)
+
+	notification, ok := st.LastNotification(email, models.NotificationPasswordReset)
+	require.True(t, ok, "reset token is not delivered")
+
+	_, err = st.Client.ConfirmPasswordReset(ctx, &authv1.ConfirmPasswordResetRequest{
+		Token:       notification.Secret,
+		NewPassword: "qwerty123",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, violatedRules(t, err, "newPassword"), passpolicy.RuleBreached)
+
+	_, err = st.Client.ConfirmPasswordReset(ctx, &authv1.ConfirmPasswordResetRequest{
+		Token:       notification.Secret,
+		NewPassword: randomFakePassword(),
+	})
+	require.NoError(t, err, "rejected password must not use the token")
+}
+
+// violatedRules returns reasons of BadRequest violations of the field
+func violatedRules(t *testing.T, err error, field string) []string {
+	t.Helper()
+
+	var rules []string
+	for _, d := range status.Convert(err).Details() {
+		br, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+
+		for _, v := range br.GetFieldViolations() {
+			assert.Equal(t, field, v.GetField())
+			assert.NotEmpty(t, v.GetDescription())
+			rules = append(rules, v.GetReason())
+		}
+	}
+
+	return rules
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/domain/models"
+	"Service/tests/suite"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPasswordReset(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	email := gofakeit.Email()
+	newPass := randomFakePassword()
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.RequestPasswordReset(ctx, &authv1.RequestPasswordResetRequest{
+		Email: email,
+	})
+	require.NoError(t, err)
+
+	notification, ok := st.LastNotification(email, models.NotificationPasswordReset)
+	require.True(t, ok, "reset token is not delivered")
+	require.NotEmpty(t, notification.Secret)
+	assert.Contains(t, notification.Body, notification.Secret)
+
+	_, err = st.Client.ConfirmPasswordReset(ctx, &authv1.ConfirmPasswordResetRequest{
+		Token:       notification.Secret,
+		NewPassword: newPass,
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.ConfirmPasswordReset(ctx, &authv1.ConfirmPasswordResetRequest{
+		Token:       notification.Secret,
+		NewPassword: randomFakePassword(),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "reset token is single-use")
+
+	introspection, err := st.Introspect(ctx, respSignUp.GetAccessToken())
+	require.NoError(t, err)
+	assert.False(t, introspection.GetActive(), "sessions are revoked")
+
+	_, err = st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: newPass,
+	})
+	require.NoError(t, err)
+}
+
+func TestPasswordResetUnknownEmail(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	email := gofakeit.Email()
+	_, err := st.Client.RequestPasswordReset(ctx, &authv1.RequestPasswordResetRequest{
+		Email: email,
+	})
+	require.NoError(t, err)
+
+	_, ok := st.LastNotification(email, models.NotificationPasswordReset)
+	assert.False(t, ok)
+
+	_, err = st.Client.ConfirmPasswordReset(ctx, &authv1.ConfirmPasswordResetRequest{
+		Token:       "unknown-token",
+		NewPassword: randomFakePassword(),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestPasswordResetThrottled(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	email := gofakeit.Email()
+
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.RequestPasswordReset(ctx, &authv1.RequestPasswordResetRequest{
+		Email: email,
+	})
+	require.NoError(t, err)
+
+	first, ok := st.LastNotification(email, models.NotificationPasswordReset)
+	require.True(t, ok, "reset token is not delivered")
+
+	_, err = st.Client.RequestPasswordReset(ctx, &authv1.RequestPasswordResetRequest{
+		Email: email,
+	})
+	require.NoError(t, err, "throttled request is not reported")
+
+	last, ok := st.LastNotification(email, models.NotificationPasswordReset)
+	require.True(t, ok)
+	assert.Equal(t, first.Secret, last.Secret, "reset token is issued within reset interval")
+
+	_, err = st.Client.ConfirmPasswordReset(ctx, &authv1.ConfirmPasswordResetRequest{
+		Token:       first.Secret,
+		NewPassword: randomFakePassword(),
+	})
+	require.NoError(t, err, "first reset token stops working")
+}
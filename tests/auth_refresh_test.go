@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/tests/suite"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateTokensRotation(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName(),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, respSignUp.GetRefreshToken(), ".", "refresh token must be opaque")
+
+	respUpdate, err := st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{
+		RefreshToken: respSignUp.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, respSignUp.GetRefreshToken(), respUpdate.GetRefreshToken())
+
+	respUpdate, err = st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{
+		RefreshToken: respUpdate.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, respUpdate.GetAccessToken())
+}
+
+func TestUpdateTokensReuseRevokesFamily(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName(),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	respUpdate, err := st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{
+		RefreshToken: respSignUp.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{
+		RefreshToken: respSignUp.GetRefreshToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{
+		RefreshToken: respUpdate.GetRefreshToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	introspection, err := st.Introspect(ctx, respUpdate.GetAccessToken())
+	require.NoError(t, err)
+	assert.False(t, introspection.GetActive())
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/tests/suite"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSessionsListAndRevoke(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	pass := randomFakePassword()
+
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	introspection, err := st.Introspect(ctx, respLogin.GetAccessToken())
+	require.NoError(t, err)
+	uuid := introspection.GetUuid()
+	ctx = suite.WithToken(ctx, respLogin.GetAccessToken())
+
+	respSessions, err := st.Client.Sessions(ctx, &authv1.SessionsRequest{Uuid: uuid})
+	require.NoError(t, err)
+	require.Len(t, respSessions.GetSessions(), 2)
+
+	for _, session := range respSessions.GetSessions() {
+		assert.NotEmpty(t, session.GetId())
+		assert.NotEmpty(t, session.GetIp())
+		assert.Contains(t, session.GetUserAgent(), "grpc-go")
+		assert.NotZero(t, session.GetCreatedAt())
+	}
+
+	claims, err := st.Claims(respLogin.GetAccessToken())
+	require.NoError(t, err)
+
+	// the session of the login authorizes requests, so the other one is revoked
+	revoked := respSessions.GetSessions()[0].GetId()
+	if revoked == claims.SessionID {
+		revoked = respSessions.GetSessions()[1].GetId()
+	}
+	_, err = st.Client.RevokeSession(ctx, &authv1.RevokeSessionRequest{
+		Uuid:      uuid,
+		SessionId: revoked,
+	})
+	require.NoError(t, err)
+
+	respSessions, err = st.Client.Sessions(ctx, &authv1.SessionsRequest{Uuid: uuid})
+	require.NoError(t, err)
+	require.Len(t, respSessions.GetSessions(), 1)
+	assert.NotEqual(t, revoked, respSessions.GetSessions()[0].GetId())
+
+	_, err = st.Client.RevokeSession(ctx, &authv1.RevokeSessionRequest{
+		Uuid:      uuid + 1,
+		SessionId: respSessions.GetSessions()[0].GetId(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"Service/internal/config"
 	"Service/tests/suite"
 	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
 	"github.com/brianvoe/gofakeit"
@@ -12,7 +13,7 @@ import (
 )
 
 func TestSignUpLoginPositive(t *testing.T) {
-	ctx, st := suite.NewSuiteAuth(t)
+	ctx, st := suite.NewSuiteAuth(t, config.New())
 
 	login := gofakeit.FirstName()
 	email := gofakeit.Email()
@@ -25,7 +26,7 @@ func TestSignUpLoginPositive(t *testing.T) {
 		Password: pass,
 	})
 	require.NoError(t, err)
-	tokenSignUp := respSignUp.GetToken()
+	tokenSignUp := respSignUp.GetAccessToken()
 	require.NotEmpty(t, tokenSignUp)
 
 	loginTime := time.Now()
@@ -34,17 +35,13 @@ func TestSignUpLoginPositive(t *testing.T) {
 		Password: pass,
 	})
 	require.NoError(t, err)
-	tokenLogin := respLogin.GetToken()
+	tokenLogin := respLogin.GetAccessToken()
 	require.NotEmpty(t, tokenLogin)
 
-	parsedSignUp, err := jwt.Parse(tokenSignUp, func(token *jwt.Token) (interface{}, error) {
-		return []byte(st.Cfg.Secret), nil
-	})
+	parsedSignUp, err := jwt.Parse(tokenSignUp, st.KeyFunc)
 	require.NoError(t, err)
 
-	parsedLogin, err := jwt.Parse(tokenLogin, func(token *jwt.Token) (interface{}, error) {
-		return []byte(st.Cfg.Secret), nil
-	})
+	parsedLogin, err := jwt.Parse(tokenLogin, st.KeyFunc)
 	require.NoError(t, err)
 
 	clSignUp, ok := parsedSignUp.Claims.(jwt.MapClaims)
@@ -54,6 +51,22 @@ func TestSignUpLoginPositive(t *testing.T) {
 
 	assert.Equal(t, clSignUp["uuid"].(float64), clLogin["uuid"].(float64))
 	assert.Equal(t, clSignUp["login"].(string), clLogin["login"].(string))
+	assert.Equal(t, clSignUp["sub"].(string), clLogin["sub"].(string))
+
+	for _, cl := range []jwt.MapClaims{clSignUp, clLogin} {
+		iss, err := cl.GetIssuer()
+		require.NoError(t, err)
+		assert.Equal(t, st.Cfg.Issuer, iss)
+
+		aud, err := cl.GetAudience()
+		require.NoError(t, err)
+		assert.ElementsMatch(t, st.Cfg.Audiences, aud)
+
+		assert.NotEmpty(t, cl["jti"])
+		assert.NotEmpty(t, cl["iat"])
+		assert.NotEmpty(t, cl["nbf"])
+	}
+	assert.NotEqual(t, clSignUp["jti"], clLogin["jti"])
 
 	const deltaSeconds = 1
 
@@ -62,7 +75,7 @@ func TestSignUpLoginPositive(t *testing.T) {
 }
 
 func TestDoubleSignUp(t *testing.T) {
-	ctx, st := suite.NewSuiteAuth(t)
+	ctx, st := suite.NewSuiteAuth(t, config.New())
 
 	login := gofakeit.FirstName()
 	email := gofakeit.Email()
@@ -74,7 +87,7 @@ func TestDoubleSignUp(t *testing.T) {
 		Password: pass,
 	})
 	require.NoError(t, err)
-	require.NotEmpty(t, respSignUp.GetToken())
+	require.NotEmpty(t, respSignUp.GetAccessToken())
 
 	respSignUp, err = st.Client.SignUp(ctx, &authv1.SignUpRequest{
 		Login:    login,
@@ -82,7 +95,7 @@ func TestDoubleSignUp(t *testing.T) {
 		Password: pass,
 	})
 	require.Error(t, err)
-	assert.Empty(t, respSignUp.GetToken())
+	assert.Empty(t, respSignUp.GetAccessToken())
 	assert.ErrorContains(t, err, "invalid arguments")
 
 }
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/lib/totp"
+	"Service/tests/suite"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTOTPLogin(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	pass := randomFakePassword()
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	enrollment, err := st.Client.EnrollTOTP(ctx, &authv1.EnrollTOTPRequest{
+		AccessToken: respSignUp.GetAccessToken(),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, enrollment.GetSecret())
+
+	uri, err := url.Parse(enrollment.GetUri())
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, enrollment.GetSecret(), uri.Query().Get("secret"))
+
+	respLogin, err := st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+	assert.False(t, respLogin.GetMfaRequired(), "second factor is not confirmed yet")
+
+	code, err := totp.Code(enrollment.GetSecret(), time.Now())
+	require.NoError(t, err)
+
+	confirmation, err := st.Client.ConfirmTOTP(ctx, &authv1.ConfirmTOTPRequest{
+		AccessToken: respSignUp.GetAccessToken(),
+		Code:        code,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, confirmation.GetRecoveryCodes())
+
+	respLogin, err = st.Client.Login(ctx, &authv1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+	require.True(t, respLogin.GetMfaRequired())
+	assert.Empty(t, respLogin.GetAccessToken())
+	assert.Empty(t, respLogin.GetRefreshToken())
+
+	_, err = st.Client.CompleteLogin(ctx, &authv1.CompleteLoginRequest{
+		MfaToken: respLogin.GetMfaToken(),
+		Code:     code,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "code is used at confirmation")
+
+	next, err := totp.Code(enrollment.GetSecret(), time.Now().Add(totp.Period))
+	require.NoError(t, err)
+
+	completed, err := st.Client.CompleteLogin(ctx, &authv1.CompleteLoginRequest{
+		MfaToken: respLogin.GetMfaToken(),
+		Code:     next,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, completed.GetAccessToken())
+	assert.NotEmpty(t, completed.GetRefreshToken())
+
+	_, err = st.Client.CompleteLogin(ctx, &authv1.CompleteLoginRequest{
+		MfaToken: respLogin.GetMfaToken(),
+		Code:     next,
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "challenge is completed once")
+}
+
+func TestTOTPRecoveryCodes(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	pass := randomFakePassword()
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	enrollment, err := st.Client.EnrollTOTP(ctx, &authv1.EnrollTOTPRequest{
+		AccessToken: respSignUp.GetAccessToken(),
+	})
+	require.NoError(t, err)
+
+	code, err := totp.Code(enrollment.GetSecret(), time.Now())
+	require.NoError(t, err)
+
+	confirmation, err := st.Client.ConfirmTOTP(ctx, &authv1.ConfirmTOTPRequest{
+		AccessToken: respSignUp.GetAccessToken(),
+		Code:        code,
+	})
+	require.NoError(t, err)
+	recovery := confirmation.GetRecoveryCodes()[0]
+
+	_, err = st.Client.EnrollTOTP(ctx, &authv1.EnrollTOTPRequest{
+		AccessToken: respSignUp.GetAccessToken(),
+	})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	for i, want := range []codes.Code{codes.OK, codes.InvalidArgument} {
+		respLogin, err := st.Client.Login(ctx, &authv1.LoginRequest{
+			Login:    login,
+			Password: pass,
+		})
+		require.NoError(t, err)
+		require.True(t, respLogin.GetMfaRequired())
+
+		_, err = st.Client.CompleteLogin(ctx, &authv1.CompleteLoginRequest{
+			MfaToken: respLogin.GetMfaToken(),
+			Code:     recovery,
+		})
+		assert.Equal(t, want, status.Code(err), "attempt %d", i)
+	}
+}
+
+func TestTOTPWrongCodesLockLogin(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+
+	login := gofakeit.FirstName() + gofakeit.LastName()
+	pass := randomFakePassword()
+
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    gofakeit.Email(),
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	enrollment, err := st.Client.EnrollTOTP(ctx, &authv1.EnrollTOTPRequest{
+		AccessToken: respSignUp.GetAccessToken(),
+	})
+	require.NoError(t, err)
+	code, err := totp.Code(enrollment.GetSecret(), time.Now())
+	require.NoError(t, err)
+	confirmation, err := st.Client.ConfirmTOTP(ctx, &authv1.ConfirmTOTPRequest{
+		AccessToken: respSignUp.GetAccessToken(),
+		Code:        code,
+	})
+	require.NoError(t, err)
+	for _, recovery := range confirmation.GetRecoveryCodes() {
+		assert.GreaterOrEqual(t, len(strings.ReplaceAll(recovery, "-", "")), 16, "recovery code is short")
+	}
+
+	// every challenge is failed once, so only the lockout limits guessing
+	for range cfg.Lockout.Login.Threshold {
+		respLogin, err := st.Client.Login(ctx, &authv1.LoginRequest{Login: login, Password: pass})
+		require.NoError(t, err)
+		require.True(t, respLogin.GetMfaRequired())
+
+		_, err = st.Client.CompleteLogin(ctx, &authv1.CompleteLoginRequest{
+			MfaToken: respLogin.GetMfaToken(),
+			Code:     "AAAAAAAA-AAAAAAAA",
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err = st.Client.Login(ctx, &authv1.LoginRequest{Login: login, Password: pass})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "login is not locked by wrong codes")
+}
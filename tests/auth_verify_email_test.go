@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/domain/models"
+	"Service/tests/suite"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	userinfov1 "github.com/IlianBuh/SSO_Protobuf/gen/go/userinfo"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, stu := suite.NewSuiteUserInfo(t, cfg)
+
+	email := gofakeit.Email()
+	respSignUp, err := sta.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName(),
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	introspection, err := sta.Introspect(ctx, respSignUp.GetAccessToken())
+	require.NoError(t, err)
+
+	userCtx := suite.WithToken(ctx, respSignUp.GetAccessToken())
+	user, err := stu.Client.User(userCtx, &userinfov1.UserRequest{Uuid: introspection.GetUuid()})
+	require.NoError(t, err)
+	assert.False(t, user.GetUser().GetEmailVerified())
+
+	notification, ok := sta.LastNotification(email, models.NotificationEmailVerification)
+	require.True(t, ok, "verification code is not delivered")
+
+	_, err = sta.Client.VerifyEmail(ctx, &authv1.VerifyEmailRequest{
+		Code: notification.Secret,
+	})
+	require.NoError(t, err)
+
+	user, err = stu.Client.User(userCtx, &userinfov1.UserRequest{Uuid: introspection.GetUuid()})
+	require.NoError(t, err)
+	assert.True(t, user.GetUser().GetEmailVerified())
+
+	_, err = sta.Client.VerifyEmail(ctx, &authv1.VerifyEmailRequest{
+		Code: notification.Secret,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "code is single-use")
+}
+
+func TestResendVerificationThrottled(t *testing.T) {
+	ctx, st := suite.NewSuiteAuth(t, config.New())
+
+	email := gofakeit.Email()
+	_, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName(),
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	first, ok := st.LastNotification(email, models.NotificationEmailVerification)
+	require.True(t, ok, "verification code is not delivered")
+
+	_, err = st.Client.ResendVerification(ctx, &authv1.ResendVerificationRequest{
+		Email: email,
+	})
+	assert.NoError(t, err, "throttling reveals unverified emails")
+
+	last, ok := st.LastNotification(email, models.NotificationEmailVerification)
+	require.True(t, ok)
+	assert.Equal(t, first.Secret, last.Secret, "verification code is sent within resend interval")
+
+	_, err = st.Client.ResendVerification(ctx, &authv1.ResendVerificationRequest{
+		Email: gofakeit.Email(),
+	})
+	assert.NoError(t, err, "unknown emails are not revealed")
+}
+
+func TestSignUpWithRequiredVerification(t *testing.T) {
+	ctx, st := suite.NewSuiteAuthInProcess(t, config.New(), func(cfg *config.Config) {
+		cfg.Verification.Required = true
+	})
+
+	login, email, password := gofakeit.FirstName()+gofakeit.LastName(), gofakeit.Email(), randomFakePassword()
+	respSignUp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    login,
+		Email:    email,
+		Password: password,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, respSignUp.GetAccessToken(), "access token of unverified user")
+	assert.Empty(t, respSignUp.GetRefreshToken(), "refresh token of unverified user")
+
+	_, err = st.Client.Login(ctx, &authv1.LoginRequest{Login: login, Password: password})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "login of unverified user")
+
+	notification, ok := st.LastNotification(email, models.NotificationEmailVerification)
+	require.True(t, ok, "verification code is not delivered")
+	_, err = st.Client.VerifyEmail(ctx, &authv1.VerifyEmailRequest{Code: notification.Secret})
+	require.NoError(t, err)
+
+	respLogin, err := st.Client.Login(ctx, &authv1.LoginRequest{Login: login, Password: password})
+	require.NoError(t, err)
+	assert.NotEmpty(t, respLogin.GetAccessToken())
+
+	_, err = st.Client.UpdateTokens(ctx, &authv1.UpdateRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.NoError(t, err)
+}
@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/services/rbac"
+	"Service/internal/storage/sqlite"
+	"Service/tests/suite"
+	"context"
+	"net/http"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	followv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/follow"
+	rbacv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/rbac"
+	userinfov1 "github.com/IlianBuh/SSO_Protobuf/gen/go/userinfo"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type signedUpUser struct {
+	uuid         int32
+	email        string
+	accessToken  string
+	refreshToken string
+}
+
+func signUpUser(t *testing.T, st *suite.SuiteAuth) signedUpUser {
+	t.Helper()
+
+	email := gofakeit.Email()
+	resp, err := st.Client.SignUp(t.Context(), &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word(),
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	claims, err := st.Claims(resp.GetAccessToken())
+	require.NoError(t, err)
+
+	return signedUpUser{
+		uuid:         int32(claims.UUID),
+		email:        email,
+		accessToken:  resp.GetAccessToken(),
+		refreshToken: resp.GetRefreshToken(),
+	}
+}
+
+func TestFollowAuthorization(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, stf := suite.NewSuiteFollow(t, cfg)
+
+	alice := signUpUser(t, sta)
+	bob := signUpUser(t, sta)
+
+	_, err := stf.Client.Follow(ctx, &followv1.FollowRequest{Src: alice.uuid, Target: bob.uuid})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "anonymous follow")
+
+	_, err = stf.Client.Follow(
+		suite.WithToken(ctx, "not-a-token"),
+		&followv1.FollowRequest{Src: alice.uuid, Target: bob.uuid},
+	)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "follow with invalid token")
+
+	_, err = stf.Client.Follow(
+		suite.WithToken(ctx, bob.accessToken),
+		&followv1.FollowRequest{Src: alice.uuid, Target: bob.uuid},
+	)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "follow on behalf of another user")
+
+	_, err = stf.Client.Unfollow(
+		suite.WithToken(ctx, bob.accessToken),
+		&followv1.UnfollowRequest{Src: alice.uuid, Target: bob.uuid},
+	)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "unfollow on behalf of another user")
+
+	_, err = stf.Client.Follow(
+		suite.WithToken(ctx, alice.accessToken),
+		&followv1.FollowRequest{Src: alice.uuid, Target: bob.uuid},
+	)
+	require.NoError(t, err)
+
+	followers, err := stf.Client.Followers(ctx, &followv1.FollowersRequest{Uuid: bob.uuid})
+	require.NoError(t, err, "followers are public")
+	require.Len(t, followers.GetUser(), 1)
+	assert.Equal(t, alice.uuid, followers.GetUser()[0].GetUuid())
+}
+
+func TestRevokedTokenIsRejected(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, stf := suite.NewSuiteFollow(t, cfg)
+
+	alice := signUpUser(t, sta)
+	bob := signUpUser(t, sta)
+
+	_, err := sta.Client.Logout(ctx, &authv1.LogoutRequest{RefreshToken: alice.refreshToken})
+	require.NoError(t, err)
+
+	_, err = stf.Client.Follow(
+		suite.WithToken(ctx, alice.accessToken),
+		&followv1.FollowRequest{Src: alice.uuid, Target: bob.uuid},
+	)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestUserInfoHidesEmails(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, stu := suite.NewSuiteUserInfo(t, cfg)
+
+	alice := signUpUser(t, sta)
+	bob := signUpUser(t, sta)
+
+	_, err := stu.Client.User(ctx, &userinfov1.UserRequest{Uuid: bob.uuid})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "anonymous user info")
+
+	resp, err := stu.Client.User(suite.WithToken(ctx, alice.accessToken), &userinfov1.UserRequest{Uuid: bob.uuid})
+	require.NoError(t, err)
+	assert.Equal(t, bob.uuid, resp.GetUser().GetUuid())
+	assert.Empty(t, resp.GetUser().GetEmail(), "email of another user is shown")
+
+	resp, err = stu.Client.User(suite.WithToken(ctx, bob.accessToken), &userinfov1.UserRequest{Uuid: bob.uuid})
+	require.NoError(t, err)
+	assert.Equal(t, bob.email, resp.GetUser().GetEmail())
+}
+
+func TestInternalRPCAuthorization(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, stu := suite.NewSuiteUserInfo(t, cfg)
+	_, stf := suite.NewSuiteFollow(t, cfg)
+
+	alice := signUpUser(t, sta)
+	bob := signUpUser(t, sta)
+	req := &userinfov1.UsersExistRequest{Uuid: []int32{alice.uuid, bob.uuid}}
+
+	service, err := sta.ClientToken("graphql-gateway", "users:read")
+	require.NoError(t, err)
+	unscoped, err := sta.ClientToken("graphql-gateway")
+	require.NoError(t, err)
+
+	resp, err := stu.Client.UsersExist(suite.WithToken(ctx, service), req)
+	require.NoError(t, err)
+	assert.True(t, resp.GetExist())
+
+	_, err = stu.Client.UsersExist(ctx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "anonymous internal request")
+
+	_, err = stu.Client.UsersExist(suite.WithToken(ctx, alice.accessToken), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "internal request of user")
+
+	_, err = stu.Client.UsersExist(suite.WithToken(ctx, unscoped), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "internal request without scope")
+
+	_, err = stu.Client.User(suite.WithToken(ctx, service), &userinfov1.UserRequest{Uuid: bob.uuid})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "user rpc with client token")
+
+	_, err = stf.Client.Follow(
+		suite.WithToken(ctx, service),
+		&followv1.FollowRequest{Src: 0, Target: bob.uuid},
+	)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "owner rpc with client token")
+
+	info, err := sta.Introspect(ctx, service)
+	require.NoError(t, err)
+	assert.True(t, info.GetActive())
+	assert.Equal(t, "graphql-gateway", info.GetClientId())
+	assert.Equal(t, []string{"users:read"}, info.GetScopes())
+}
+
+func TestDelegatedTokenIsRejectedByFirstPartyRPCs(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, str := suite.NewSuiteRBAC(t, cfg)
+	_, stf := suite.NewSuiteFollow(t, cfg)
+	sto := suite.NewSuiteOAuth(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	user := signUpOAuthUser(sto)
+	verifier, challenge := suite.NewPKCE()
+	code, body := sto.Token(exchangeForm(authorize(t, sto, user, challenge), verifier))
+	require.Equal(t, http.StatusOK, code, body)
+	delegated := body["access_token"].(string)
+
+	// the owner of the token is admin of the service
+	_, err := db.AssignRole(ctx, user.uuid, rbac.RoleAdmin)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.RevokeRole(context.Background(), user.uuid, rbac.RoleAdmin)
+	})
+
+	other := signUpUser(t, sta)
+	uuid := int32(user.uuid)
+
+	_, err = str.Client.AssignRole(
+		suite.WithToken(ctx, delegated),
+		&rbacv1.AssignRoleRequest{Uuid: other.uuid, Role: rbac.RoleAdmin},
+	)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "assign role with delegated token")
+
+	_, err = sta.Client.LogoutAll(suite.WithToken(ctx, delegated), &authv1.LogoutAllRequest{Uuid: uuid})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "logout all with delegated token")
+
+	_, err = sta.Client.Sessions(suite.WithToken(ctx, delegated), &authv1.SessionsRequest{Uuid: uuid})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "sessions with delegated token")
+
+	_, err = stf.Client.Follow(
+		suite.WithToken(ctx, delegated),
+		&followv1.FollowRequest{Src: uuid, Target: other.uuid},
+	)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "follow with delegated token")
+}
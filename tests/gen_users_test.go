@@ -18,40 +18,46 @@ func TestGenerate(t *testing.T) {
 	ctx, sf := suite.NewSuiteFollow(t, cfg)
 
 	loginSet := make(map[string]bool)
+	var users []generatedUser
 	for range 200 {
 		login := generateLoginWord()
 		if loginSet[login] {
 			continue
 		}
+		loginSet[login] = true
 
-		_, err := s.Client.SignUp(
+		resp, err := s.Client.SignUp(
 			ctx,
 			&authv1.SignUpRequest{
 				Login:    login,
 				Email:    fmt.Sprintf("%s@example.com", login),
-				Password: "password",
+				Password: "generated-user-pass",
 			},
 		)
 		require.NoError(t, err)
 
+		claims, err := s.Claims(resp.GetAccessToken())
+		require.NoError(t, err)
+		users = append(users, generatedUser{uuid: int32(claims.UUID), token: resp.GetAccessToken()})
 	}
 
-	followList := make(map[int]struct{}, 20)
-	for i := range 200 {
+	// users follow on their own behalf, so every user makes at most as many
+	// follows as the rate limit of Follow allows at once
+	followList := make(map[int]struct{}, 10)
+	for i, user := range users {
 		clear(followList)
-		i++
-		for range rand.Int()%20 + 5 {
-			id := rand.Int()%200 + 1
+		for range rand.Int()%6 + 5 {
+			id := rand.Intn(len(users))
 			if _, ok := followList[id]; ok || i == id {
 				continue
 			}
 
 			followList[id] = struct{}{}
 			_, err := sf.Client.Follow(
-				t.Context(),
+				suite.WithToken(t.Context(), user.token),
 				&followv1.FollowRequest{
-					Src:    int32(i),
-					Target: int32(id),
+					Src:    user.uuid,
+					Target: users[id].uuid,
 				},
 			)
 			require.NoError(t, err)
@@ -60,6 +66,11 @@ func TestGenerate(t *testing.T) {
 
 }
 
+type generatedUser struct {
+	uuid  int32
+	token string
+}
+
 func generateLoginWord() string {
 	adjectives := []string{
 		"quick", "brave", "lazy", "happy", "clever", "noisy", "bright", "silent", "shiny", "rough",
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/domain/models"
+	"Service/internal/services/janitor"
+	"Service/internal/storage/sqlite"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJanitorMetricsPublished(t *testing.T) {
+	cfg := config.New()
+	require.NotEmpty(t, cfg.Metrics.Addr)
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/debug/vars", cfg.Metrics.Addr))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var vars map[string]json.RawMessage
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&vars))
+
+	for _, name := range []string{
+		"janitor_runs",
+		"janitor_failures",
+		"janitor_tokens_removed",
+		"janitor_sessions_removed",
+		"janitor_revoked_access_tokens_removed",
+		"janitor_password_resets_removed",
+		"janitor_verifications_removed",
+		"janitor_mfa_challenges_removed",
+		"janitor_login_attempts_removed",
+	} {
+		assert.Contains(t, vars, name)
+	}
+
+	var runs int64
+	require.NoError(t, json.Unmarshal(vars["janitor_runs"], &runs))
+	assert.GreaterOrEqual(t, runs, int64(1), "storage is purged at start")
+}
+
+func TestMetricsAreNotPublic(t *testing.T) {
+	cfg := config.New()
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/debug/vars", cfg.HTTP.Port))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestJanitorPurgesStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.db")
+	st := sqlite.New(path)
+	ctx := t.Context()
+	now := time.Now()
+	old := now.Add(-time.Hour)
+
+	sessions := []models.Session{
+		{ID: "expired", UserID: 1, CreatedAt: old, LastRefreshedAt: old},
+		{ID: "revoked", UserID: 1, CreatedAt: old, LastRefreshedAt: old},
+		{ID: "alive", UserID: 1, CreatedAt: old, LastRefreshedAt: old},
+		{ID: "empty", UserID: 1, CreatedAt: old, LastRefreshedAt: old},
+	}
+	for _, s := range sessions {
+		require.NoError(t, st.SaveSession(ctx, s))
+	}
+
+	tokens := []models.TrackedToken{
+		{Hash: []byte("expired"), UserID: 1, SessionID: "expired", IssuedAt: old, ExpiresAt: now.Add(-time.Minute)},
+		{Hash: []byte("revoked"), UserID: 1, SessionID: "revoked", IssuedAt: old, ExpiresAt: now.Add(time.Hour)},
+		{Hash: []byte("alive"), UserID: 1, SessionID: "alive", IssuedAt: old, ExpiresAt: now.Add(time.Hour)},
+	}
+	for _, token := range tokens {
+		require.NoError(t, st.StoreToken(ctx, token))
+	}
+	_, err := st.RevokeSession(ctx, "revoked")
+	require.NoError(t, err)
+
+	require.NoError(t, st.SaveRevokedAccessTokens(ctx, []models.AccessTokenRef{
+		{JTI: "expired-jti", ExpiresAt: now.Add(-time.Minute)},
+		{JTI: "alive-jti", ExpiresAt: now.Add(time.Hour)},
+	}))
+
+	j, err := janitor.New(slog.New(slog.NewTextHandler(io.Discard, nil)), st, time.Hour, 1)
+	require.NoError(t, err)
+	j.Start()
+	defer j.Stop()
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ids := func(query string) []string {
+		rows, err := db.QueryContext(ctx, query)
+		require.NoError(t, err)
+		defer rows.Close()
+
+		res := make([]string, 0)
+		for rows.Next() {
+			var id string
+			require.NoError(t, rows.Scan(&id))
+			res = append(res, id)
+		}
+		require.NoError(t, rows.Err())
+
+		return res
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(ids("SELECT id FROM sessions;")) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, []string{"alive"}, ids("SELECT session_id FROM tokens;"))
+	assert.Equal(t, []string{"alive"}, ids("SELECT id FROM sessions;"))
+	assert.Equal(t, []string{"alive-jti"}, ids("SELECT jti FROM revoked_access_tokens;"))
+}
+
+func TestJanitorRequiresPositiveSettings(t *testing.T) {
+	st := sqlite.New(filepath.Join(t.TempDir(), "auth.db"))
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	_, err := janitor.New(log, st, 0, 1)
+	assert.Error(t, err, "zero interval")
+
+	_, err = janitor.New(log, st, time.Hour, 0)
+	assert.Error(t, err, "zero batch size")
+
+	_, err = janitor.New(log, st, time.Hour, -1)
+	assert.Error(t, err, "negative batch size")
+}
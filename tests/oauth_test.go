@@ -0,0 +1,379 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/tests/suite"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type oauthUser struct {
+	uuid     uint64
+	login    string
+	email    string
+	password string
+}
+
+func signUpOAuthUser(st *suite.SuiteOAuth) oauthUser {
+	user := oauthUser{
+		login:    gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word(),
+		email:    gofakeit.Email(),
+		password: randomFakePassword(),
+	}
+	user.uuid = st.SignUp(user.login, user.email, user.password)
+
+	return user
+}
+
+func authorizeQuery(challenge string) url.Values {
+	return url.Values{
+		"response_type":         {"code"},
+		"client_id":             {suite.OAuthClientID},
+		"redirect_uri":          {suite.OAuthRedirectURI},
+		"scope":                 {"profile"},
+		"state":                 {"xyz"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+}
+
+// authorize signs the user in on the login page and returns the code the
+// client is redirected with
+func authorize(t *testing.T, st *suite.SuiteOAuth, user oauthUser, challenge string) string {
+	t.Helper()
+
+	return authorizeWith(t, st, user, authorizeQuery(challenge))
+}
+
+// authorizeWith signs the user in with the authorization request
+func authorizeWith(t *testing.T, st *suite.SuiteOAuth, user oauthUser, form url.Values) string {
+	t.Helper()
+
+	form.Set("login", user.login)
+	form.Set("password", user.password)
+	form.Set("action", "allow")
+
+	resp := st.PostForm("/authorize", form)
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "app.example", location.Host)
+	require.Equal(t, "xyz", location.Query().Get("state"))
+	require.NotEmpty(t, location.Query().Get("code"), location.String())
+
+	return location.Query().Get("code")
+}
+
+func exchangeForm(code, verifier string) url.Values {
+	return url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {suite.OAuthClientID},
+		"code":          {code},
+		"redirect_uri":  {suite.OAuthRedirectURI},
+		"code_verifier": {verifier},
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	cfg := config.New()
+	st := suite.NewSuiteOAuth(t, cfg)
+	user := signUpOAuthUser(st)
+	verifier, challenge := suite.NewPKCE()
+
+	resp := st.Get("/authorize", authorizeQuery(challenge))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Test App")
+	assert.Contains(t, string(page), `name="password"`)
+
+	code := authorize(t, st, user, challenge)
+
+	status, body := st.Token(exchangeForm(code, verifier))
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "Bearer", body["token_type"])
+	assert.Equal(t, "profile", body["scope"])
+	assert.EqualValues(t, cfg.TokenTTL.Seconds(), body["expires_in"])
+
+	claims, err := (&suite.SuiteAuth{Cfg: cfg}).Claims(body["access_token"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, user.uuid, claims.UUID)
+	assert.Equal(t, suite.OAuthClientID, claims.ClientID)
+	assert.Equal(t, "profile", claims.Scope)
+	refreshToken := body["refresh_token"].(string)
+
+	status, body = st.Token(exchangeForm(code, verifier))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "code is reused")
+
+	status, body = st.Token(url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {suite.OAuthOtherClient},
+		"refresh_token": {refreshToken},
+	})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "refresh by another client")
+
+	status, body = st.Token(url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {suite.OAuthClientID},
+		"refresh_token": {refreshToken},
+	})
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "profile", body["scope"])
+	assert.NotEqual(t, refreshToken, body["refresh_token"])
+
+	claims, err = (&suite.SuiteAuth{Cfg: cfg}).Claims(body["access_token"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, suite.OAuthClientID, claims.ClientID)
+	assert.Equal(t, "profile", claims.Scope)
+}
+
+func TestAuthorizationCodeRequiresVerifier(t *testing.T) {
+	cfg := config.New()
+	st := suite.NewSuiteOAuth(t, cfg)
+	user := signUpOAuthUser(st)
+	verifier, challenge := suite.NewPKCE()
+	otherVerifier, _ := suite.NewPKCE()
+
+	code := authorize(t, st, user, challenge)
+	status, body := st.Token(exchangeForm(code, otherVerifier))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "wrong verifier")
+
+	code = authorize(t, st, user, challenge)
+	form := exchangeForm(code, verifier)
+	form.Set("redirect_uri", "https://app.example/other")
+	status, body = st.Token(form)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "another redirect uri")
+
+	code = authorize(t, st, user, challenge)
+	form = exchangeForm(code, verifier)
+	form.Set("client_id", suite.OAuthOtherClient)
+	status, body = st.Token(form)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"], "code of another client")
+
+	status, body = st.Token(url.Values{"grant_type": {"password"}, "client_id": {suite.OAuthClientID}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "unsupported_grant_type", body["error"])
+}
+
+func TestAuthorizeValidation(t *testing.T) {
+	cfg := config.New()
+	st := suite.NewSuiteOAuth(t, cfg)
+	user := signUpOAuthUser(st)
+	_, challenge := suite.NewPKCE()
+
+	tests := []struct {
+		name     string
+		change   func(url.Values)
+		redirect bool
+		err      string
+	}{
+		{
+			name:   "unknown client",
+			change: func(q url.Values) { q.Set("client_id", "unknown") },
+		},
+		{
+			name:   "unregistered redirect uri",
+			change: func(q url.Values) { q.Set("redirect_uri", "https://evil.example/callback") },
+		},
+		{
+			name:   "redirect uri of another client",
+			change: func(q url.Values) { q.Set("redirect_uri", "https://other.example/callback") },
+		},
+		{
+			name:     "missing code challenge",
+			change:   func(q url.Values) { q.Del("code_challenge") },
+			redirect: true,
+			err:      "invalid_request",
+		},
+		{
+			name:     "plain code challenge",
+			change:   func(q url.Values) { q.Set("code_challenge_method", "plain") },
+			redirect: true,
+			err:      "invalid_request",
+		},
+		{
+			name:     "unsupported response type",
+			change:   func(q url.Values) { q.Set("response_type", "token") },
+			redirect: true,
+			err:      "unsupported_response_type",
+		},
+		{
+			name:     "scope is not allowed",
+			change:   func(q url.Values) { q.Set("scope", "profile admin") },
+			redirect: true,
+			err:      "invalid_scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := authorizeQuery(challenge)
+			tt.change(query)
+
+			resp := st.Get("/authorize", query)
+			if !tt.redirect {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				assert.Empty(t, resp.Header.Get("Location"))
+				return
+			}
+
+			require.Equal(t, http.StatusFound, resp.StatusCode)
+			location, err := url.Parse(resp.Header.Get("Location"))
+			require.NoError(t, err)
+			assert.Equal(t, "app.example", location.Host)
+			assert.Equal(t, tt.err, location.Query().Get("error"))
+			assert.Equal(t, "xyz", location.Query().Get("state"))
+		})
+	}
+
+	form := authorizeQuery(challenge)
+	form.Set("login", user.login)
+	form.Set("password", "wrong-password")
+	form.Set("action", "allow")
+	resp := st.PostForm("/authorize", form)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Invalid login or password")
+
+	form.Set("action", "deny")
+	resp = st.PostForm("/authorize", form)
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "access_denied", location.Query().Get("error"))
+}
+
+func TestClientCredentials(t *testing.T) {
+	cfg := config.New()
+	st := suite.NewSuiteOAuth(t, cfg)
+	form := url.Values{"grant_type": {"client_credentials"}, "scope": {"users:read"}}
+
+	status, body := st.TokenWithBasic(suite.OAuthServiceID, st.ServiceSecret, form)
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "Bearer", body["token_type"])
+	assert.Equal(t, "users:read", body["scope"])
+	assert.NotContains(t, body, "refresh_token")
+
+	claims, err := (&suite.SuiteAuth{Cfg: cfg}).Claims(body["access_token"].(string))
+	require.NoError(t, err)
+	assert.True(t, claims.IsClient())
+	assert.Equal(t, suite.OAuthServiceID, claims.Subject)
+	assert.Equal(t, suite.OAuthServiceID, claims.ClientID)
+	assert.Zero(t, claims.UUID)
+	assert.Equal(t, "users:read", claims.Scope)
+
+	status, body = st.Token(url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {suite.OAuthServiceID},
+		"client_secret": {st.ServiceSecret},
+	})
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "users:read users:write", body["scope"], "scopes default to allowed ones")
+
+	status, body = st.TokenWithBasic(suite.OAuthServiceID, "wrong-secret", form)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_client", body["error"], "wrong secret")
+
+	status, body = st.Token(url.Values{"grant_type": {"client_credentials"}, "client_id": {suite.OAuthServiceID}})
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_client", body["error"], "missing secret")
+
+	scoped := url.Values{"grant_type": {"client_credentials"}, "scope": {"users:read admin"}}
+	status, body = st.TokenWithBasic(suite.OAuthServiceID, st.ServiceSecret, scoped)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_scope", body["error"], "scope is not allowed")
+
+	status, body = st.Token(url.Values{"grant_type": {"client_credentials"}, "client_id": {suite.OAuthClientID}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "unauthorized_client", body["error"], "public client")
+}
+
+func TestOpenIDConnect(t *testing.T) {
+	cfg := config.New()
+	st := suite.NewSuiteOAuth(t, cfg)
+	user := signUpOAuthUser(st)
+	verifier, challenge := suite.NewPKCE()
+
+	query := authorizeQuery(challenge)
+	query.Set("scope", "openid profile email")
+	query.Set("nonce", "n-0S6_WzA2Mj")
+	code := authorizeWith(t, st, user, query)
+
+	status, body := st.Token(exchangeForm(code, verifier))
+	require.Equal(t, http.StatusOK, status, body)
+	require.NotEmpty(t, body["id_token"])
+	accessToken := body["access_token"].(string)
+
+	id, err := (&suite.SuiteAuth{Cfg: cfg}).IDClaims(body["id_token"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Issuer, id.Issuer)
+	assert.Equal(t, strconv.FormatUint(user.uuid, 10), id.Subject)
+	assert.Equal(t, []string{suite.OAuthClientID}, []string(id.Audience))
+	assert.Equal(t, user.login, id.PreferredUsername)
+	assert.Equal(t, user.email, id.Email)
+	require.NotNil(t, id.EmailVerified)
+	assert.False(t, *id.EmailVerified)
+	assert.Equal(t, "n-0S6_WzA2Mj", id.Nonce)
+	require.NotNil(t, id.AuthTime)
+	assert.WithinDuration(t, time.Now(), id.AuthTime.Time, time.Minute)
+
+	resp, info := st.UserInfo(accessToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, id.Subject, info["sub"])
+	assert.Equal(t, user.login, info["preferred_username"])
+	assert.Equal(t, user.email, info["email"])
+	assert.Equal(t, false, info["email_verified"])
+
+	resp, _ = st.UserInfo(body["id_token"].(string))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "id token is used as access token")
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "invalid_token")
+
+	resp = st.Get("/.well-known/openid-configuration", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	discovery := make(map[string]any)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&discovery))
+	assert.Equal(t, cfg.Issuer+"/userinfo", discovery["userinfo_endpoint"])
+	assert.Contains(t, discovery["scopes_supported"], "openid")
+}
+
+func TestUserInfoRequiresOpenIDScope(t *testing.T) {
+	cfg := config.New()
+	st := suite.NewSuiteOAuth(t, cfg)
+	user := signUpOAuthUser(st)
+	verifier, challenge := suite.NewPKCE()
+
+	code := authorize(t, st, user, challenge)
+	status, body := st.Token(exchangeForm(code, verifier))
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Empty(t, body["id_token"], "id token without openid scope")
+
+	resp, _ := st.UserInfo(body["access_token"].(string))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "insufficient_scope")
+
+	status, body = st.TokenWithBasic(suite.OAuthServiceID, st.ServiceSecret, url.Values{
+		"grant_type": {"client_credentials"},
+	})
+	require.Equal(t, http.StatusOK, status, body)
+	resp, _ = st.UserInfo(body["access_token"].(string))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "client token")
+
+	resp, _ = st.UserInfo("not-a-token")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/grpc/ratelimit"
+	"Service/tests/suite"
+	"strconv"
+	"testing"
+	"time"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	followv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/follow"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const followMethod = "/follow.Follow/Follow"
+
+func TestRateLimitPerSubject(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, stf := suite.NewSuiteFollow(t, cfg)
+
+	limit := cfg.RateLimit.Methods[followMethod].PerSubject
+	require.Positive(t, limit.Burst, "follow is not limited per subject")
+
+	first := signUpForRateLimit(t, sta)
+	second := signUpForRateLimit(t, sta)
+
+	// negative target is rejected after rate limits are checked
+	follow := func(token string) (metadata.MD, error) {
+		claims, err := sta.Claims(token)
+		require.NoError(t, err)
+
+		var header metadata.MD
+		_, err = stf.Client.Follow(
+			suite.WithToken(ctx, token),
+			&followv1.FollowRequest{Src: int32(claims.UUID), Target: -1},
+			grpc.Header(&header),
+		)
+		return header, err
+	}
+
+	var (
+		header metadata.MD
+		err    error
+	)
+	for range limit.Burst + 1 {
+		header, err = follow(first)
+		if status.Code(err) == codes.ResourceExhausted {
+			break
+		}
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+	require.Equal(t, codes.ResourceExhausted, status.Code(err), "burst is not limited")
+
+	values := header.Get(ratelimit.RetryAfterKey)
+	require.Len(t, values, 1, "retry-after is not sent")
+	seconds, err := strconv.Atoi(values[0])
+	require.NoError(t, err)
+	assert.Positive(t, seconds)
+	assert.LessOrEqual(t, seconds, int(limit.Every.Seconds())+1)
+
+	_, err = follow(second)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "other subject is limited")
+}
+
+func TestRateLimitPerIPBeforeAuthentication(t *testing.T) {
+	const burst = 2
+
+	ctx, st := suite.NewSuiteAuthInProcess(t, config.New(), func(cfg *config.Config) {
+		cfg.RateLimit.Methods = map[string]config.RateLimitMethodObj{
+			authv1.Auth_Sessions_FullMethodName: {
+				PerIP: config.RateLimitBucketObj{Every: time.Hour, Burst: burst},
+			},
+		}
+	})
+
+	sessions := func() error {
+		_, err := st.Client.Sessions(suite.WithToken(ctx, "invalid-token"), &authv1.SessionsRequest{Uuid: 1})
+		return err
+	}
+
+	for range burst {
+		require.Equal(t, codes.Unauthenticated, status.Code(sessions()))
+	}
+	assert.Equal(t, codes.ResourceExhausted, status.Code(sessions()), "requests with invalid tokens are not limited")
+}
+
+func signUpForRateLimit(t *testing.T, st *suite.SuiteAuth) string {
+	t.Helper()
+
+	resp, err := st.Client.SignUp(t.Context(), &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName() + gofakeit.Word(),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	return resp.GetAccessToken()
+}
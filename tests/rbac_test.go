@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"Service/internal/config"
+	"Service/internal/services/rbac"
+	"Service/internal/storage/sqlite"
+	"Service/tests/suite"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	rbacv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRoleManagement(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, str := suite.NewSuiteRBAC(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	admin := signUpUser(t, sta)
+	user := signUpUser(t, sta)
+
+	// the first admin is assigned out of band, like the bootstrap does
+	_, err := db.AssignRole(ctx, uint64(admin.uuid), rbac.RoleAdmin)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.RevokeRole(ctx, uint64(admin.uuid), rbac.RoleAdmin)
+	})
+
+	_, err = str.Client.AssignRole(ctx, &rbacv1.AssignRoleRequest{Uuid: user.uuid, Role: "moderator"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "anonymous assign")
+
+	_, err = str.Client.AssignRole(
+		suite.WithToken(ctx, user.accessToken),
+		&rbacv1.AssignRoleRequest{Uuid: user.uuid, Role: "moderator"},
+	)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "assign by non-admin")
+
+	_, err = str.Client.Roles(
+		suite.WithToken(ctx, user.accessToken),
+		&rbacv1.RolesRequest{Uuid: admin.uuid},
+	)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "roles of another user by non-admin")
+
+	_, err = str.Client.AssignRole(
+		suite.WithToken(ctx, admin.accessToken),
+		&rbacv1.AssignRoleRequest{Uuid: user.uuid, Role: "no-such-role"},
+	)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "unknown role")
+
+	_, err = str.Client.AssignRole(
+		suite.WithToken(ctx, admin.accessToken),
+		&rbacv1.AssignRoleRequest{Uuid: user.uuid, Role: "moderator"},
+	)
+	require.NoError(t, err)
+
+	roles, err := str.Client.Roles(
+		suite.WithToken(ctx, user.accessToken),
+		&rbacv1.RolesRequest{Uuid: user.uuid},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"moderator"}, roles.GetRoles())
+	assert.Equal(t, []string{"blog:moderate"}, roles.GetPermissions())
+
+	tokens, err := sta.Client.UpdateTokens(ctx, &authv1.UpdateRequest{RefreshToken: user.refreshToken})
+	require.NoError(t, err)
+
+	claims, err := sta.Claims(tokens.GetAccessToken())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"moderator"}, claims.Roles)
+
+	info, err := sta.Introspect(ctx, tokens.GetAccessToken())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"moderator"}, info.GetRoles())
+
+	_, err = str.Client.RevokeRole(
+		suite.WithToken(ctx, admin.accessToken),
+		&rbacv1.RevokeRoleRequest{Uuid: user.uuid, Role: "moderator"},
+	)
+	require.NoError(t, err)
+
+	tokens, err = sta.Client.UpdateTokens(ctx, &authv1.UpdateRequest{RefreshToken: tokens.GetRefreshToken()})
+	require.NoError(t, err)
+
+	claims, err = sta.Claims(tokens.GetAccessToken())
+	require.NoError(t, err)
+	assert.Empty(t, claims.Roles)
+}
+
+func TestRevokeLastAdmin(t *testing.T) {
+	cfg := config.New()
+	ctx, sta := suite.NewSuiteAuth(t, cfg)
+	_, str := suite.NewSuiteRBAC(t, cfg)
+	db := sqlite.New(cfg.StoragePath)
+
+	admin := signUpUser(t, sta)
+	_, err := db.AssignRole(ctx, uint64(admin.uuid), rbac.RoleAdmin)
+	require.NoError(t, err)
+
+	second := signUpUser(t, sta)
+	_, err = str.Client.AssignRole(
+		suite.WithToken(ctx, admin.accessToken),
+		&rbacv1.AssignRoleRequest{Uuid: second.uuid, Role: rbac.RoleAdmin},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.RevokeRole(ctx, uint64(second.uuid), rbac.RoleAdmin)
+	})
+
+	_, err = str.Client.RevokeRole(
+		suite.WithToken(ctx, second.accessToken),
+		&rbacv1.RevokeRoleRequest{Uuid: admin.uuid, Role: rbac.RoleAdmin},
+	)
+	require.NoError(t, err)
+
+	// the revoked admin loses permissions before the token expires
+	_, err = str.Client.AssignRole(
+		suite.WithToken(ctx, admin.accessToken),
+		&rbacv1.AssignRoleRequest{Uuid: admin.uuid, Role: rbac.RoleAdmin},
+	)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "assign by revoked admin")
+
+	admins, err := db.RoleMembers(ctx, rbac.RoleAdmin)
+	require.NoError(t, err)
+	if admins > 1 {
+		t.Skip("storage has other admins")
+	}
+
+	_, err = str.Client.RevokeRole(
+		suite.WithToken(ctx, second.accessToken),
+		&rbacv1.RevokeRoleRequest{Uuid: second.uuid, Role: rbac.RoleAdmin},
+	)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "revoke last admin")
+}
+
+func TestBootstrapAdminByUUID(t *testing.T) {
+	st := sqlite.New(filepath.Join(t.TempDir(), "auth.db"))
+	ctx := t.Context()
+	roles := rbac.New(slog.New(slog.NewTextHandler(io.Discard, nil)), st, st)
+	require.NoError(t, roles.SyncRoles(ctx, nil))
+
+	_, err := roles.Bootstrap(ctx, 1)
+	assert.ErrorIs(t, err, rbac.ErrUserNotFound)
+
+	uuid, err := st.Save(ctx, "admin", "admin@example.com", []byte("hash"))
+	require.NoError(t, err)
+
+	assigned, err := roles.Bootstrap(ctx, uuid)
+	require.NoError(t, err)
+	assert.True(t, assigned)
+
+	userRoles, err := st.UserRoles(ctx, uuid)
+	require.NoError(t, err)
+	assert.Contains(t, userRoles, rbac.RoleAdmin)
+
+	assigned, err = roles.Bootstrap(ctx, uuid)
+	require.NoError(t, err)
+	assert.False(t, assigned, "admin is assigned while there is one")
+}
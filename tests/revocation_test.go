@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"Service/internal/domain/models"
+	"Service/internal/services/revocation"
+	"Service/internal/storage/sqlite"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationCoversLeeway(t *testing.T) {
+	st := sqlite.New(filepath.Join(t.TempDir(), "auth.db"))
+	ctx := t.Context()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Now()
+	leeway := 30 * time.Second
+
+	require.NoError(t, st.SaveSession(ctx, models.Session{ID: "session", UserID: 1, CreatedAt: now, LastRefreshedAt: now}))
+	tokens := []models.TrackedToken{
+		{
+			Hash: []byte("accepted"), UserID: 1, SessionID: "session", IssuedAt: now, ExpiresAt: now.Add(time.Hour),
+			Access: models.AccessTokenRef{JTI: "accepted-jti", ExpiresAt: now.Add(-10 * time.Second)},
+		},
+		{
+			Hash: []byte("rejected"), UserID: 1, SessionID: "session", IssuedAt: now, ExpiresAt: now.Add(time.Hour),
+			Access: models.AccessTokenRef{JTI: "rejected-jti", ExpiresAt: now.Add(-time.Minute)},
+		},
+	}
+	for _, token := range tokens {
+		require.NoError(t, st.StoreToken(ctx, token))
+	}
+
+	refs, err := st.SessionAccessTokens(ctx, "session", now.Add(-leeway))
+	require.NoError(t, err)
+	require.Len(t, refs, 1, "expired tokens accepted within leeway are outstanding")
+	assert.Equal(t, "accepted-jti", refs[0].JTI)
+
+	refs, err = st.UserAccessTokens(ctx, 1, "", now.Add(-leeway))
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	assert.Equal(t, "accepted-jti", refs[0].JTI)
+
+	list, err := revocation.New(ctx, log, st, leeway)
+	require.NoError(t, err)
+	require.NoError(t, list.Revoke(ctx, refs...))
+	assert.True(t, list.IsRevoked("accepted-jti"), "entry is dropped before leeway passes")
+
+	reloaded, err := revocation.New(ctx, log, st, leeway)
+	require.NoError(t, err)
+	assert.True(t, reloaded.IsRevoked("accepted-jti"), "entry is not loaded within leeway")
+}
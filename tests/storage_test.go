@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"Service/internal/storage/sqlite"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// baselineSchema is the schema of databases created by the first version of
+// the service
+const baselineSchema = `
+	CREATE TABLE users(
+		uuid INTEGER PRIMARY KEY,
+		login TEXT NOT NULL UNIQUE,
+		email TEXT NOT NULL UNIQUE,
+		passhash BLOB NOT NULL
+	);
+
+	CREATE INDEX idx_uuid ON users(uuid);
+
+	CREATE TABLE followings (
+		follower INTEGER NOT NULL,
+		followee INTEGER NOT NULL,
+		PRIMARY KEY(follower, followee),
+		FOREIGN KEY (follower) REFERENCES users(uuid) ON DELETE CASCADE,
+		FOREIGN KEY (followee) REFERENCES users(uuid) ON DELETE CASCADE
+	);
+
+	CREATE TABLE tokens (
+		id integer PRIMARY KEY,
+		refresh_token TEXT NOT NULL,
+		access_token TEXT NOT NULL
+	);
+
+	INSERT INTO users(uuid, login, email, passhash) VALUES(1, 'Alice', 'Alice@Example.com', x'00');
+	INSERT INTO tokens(refresh_token, access_token) VALUES('refresh', 'access');
+`
+
+func TestStorageMigratesBaselineDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.db")
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec(baselineSchema)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var st *sqlite.Storage
+	require.NotPanics(t, func() { st = sqlite.New(path) })
+
+	user, err := st.UserByLogin(t.Context(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), user.UUID)
+	assert.False(t, user.EmailVerified)
+
+	revoked, err := st.RevokeUserTokens(t.Context(), 1)
+	require.NoError(t, err, "tokens table is recreated")
+	assert.Zero(t, revoked)
+
+	require.NotPanics(t, func() { sqlite.New(path) }, "migrated database is opened again")
+}
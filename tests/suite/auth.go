@@ -1,16 +1,31 @@
 package suite
 
 import (
+	"Service/internal/app"
 	"Service/internal/config"
+	ssojwt "Service/internal/lib/jwt"
 	"context"
+	"fmt"
 	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/golang-jwt/jwt/v5"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"io"
+	"log/slog"
 	"net"
+	"path/filepath"
 	"strconv"
 	"testing"
 )
 
+const (
+	// IntrospectClientID is the backend client of the config allowed to
+	// introspect tokens
+	IntrospectClientID = "graphql-gateway"
+	IntrospectScope    = "tokens:introspect"
+)
+
 type SuiteAuth struct {
 	*testing.T
 	Cfg    *config.Config
@@ -42,3 +57,125 @@ func NewSuiteAuth(t *testing.T, cfg *config.Config) (context.Context, *SuiteAuth
 		Cfg:    cfg,
 	}
 }
+
+// NewSuiteAuthInProcess runs gRPC application of the service in-process with
+// its own storage and the config changed by configure, e.g. to test rules
+// disabled in the shared config
+func NewSuiteAuthInProcess(
+	t *testing.T,
+	cfg *config.Config,
+	configure func(cfg *config.Config),
+) (context.Context, *SuiteAuth) {
+	t.Helper()
+	t.Parallel()
+
+	local := *cfg
+	local.StoragePath = filepath.Join(t.TempDir(), "auth.db")
+	local.Notifier.Path = filepath.Join(t.TempDir(), "outbox.jsonl")
+	local.Metrics.Addr = ""
+	configure(&local)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	application := app.New(slog.New(slog.NewTextHandler(io.Discard, nil)), &local)
+	go func() { _ = application.GRPCApp.Serve(lis) }()
+	t.Cleanup(application.GRPCApp.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	cc, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect to grpc server: %v", err)
+	}
+
+	return ctx, &SuiteAuth{
+		Client: authv1.NewAuthClient(cc),
+		Cfg:    &local,
+	}
+}
+
+// KeyFunc resolves public key to verify tokens issued by the service using
+// keys listed in config
+func (s *SuiteAuth) KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	for _, k := range s.Cfg.Keys {
+		if k.ID != kid {
+			continue
+		}
+
+		key, err := ssojwt.LoadKey(k.ID, k.Path, k.ActiveFrom)
+		if err != nil {
+			return nil, err
+		}
+
+		return key.Public(), nil
+	}
+
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// Claims parses the access token issued by the service
+func (s *SuiteAuth) Claims(token string) (ssojwt.Claims, error) {
+	var claims ssojwt.Claims
+	if _, err := jwt.ParseWithClaims(token, &claims, s.KeyFunc); err != nil {
+		return ssojwt.Claims{}, err
+	}
+
+	return claims, nil
+}
+
+// IDClaims verifies the ID token with keys listed in config and returns its
+// claims
+func (s *SuiteAuth) IDClaims(token string) (ssojwt.IDClaims, error) {
+	var claims ssojwt.IDClaims
+	if _, err := jwt.ParseWithClaims(token, &claims, s.KeyFunc); err != nil {
+		return ssojwt.IDClaims{}, err
+	}
+
+	return claims, nil
+}
+
+// Introspect introspects the token on behalf of the backend client allowed
+// to introspect tokens
+func (s *SuiteAuth) Introspect(ctx context.Context, token string) (*authv1.IntrospectResponse, error) {
+	service, err := s.ClientToken(IntrospectClientID, IntrospectScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Client.Introspect(WithToken(ctx, service), &authv1.IntrospectRequest{Token: token})
+}
+
+// ClientToken issues access token of the client acting on its own behalf
+// with keys listed in config
+func (s *SuiteAuth) ClientToken(clientID string, scopes ...string) (string, error) {
+	keys := make([]*ssojwt.Key, 0, len(s.Cfg.Keys))
+	for _, k := range s.Cfg.Keys {
+		key, err := ssojwt.LoadKey(k.ID, k.Path, k.ActiveFrom)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+	}
+
+	ring, err := ssojwt.NewKeyRing(s.Cfg.TokenTTL, keys...)
+	if err != nil {
+		return "", err
+	}
+
+	issuer := &ssojwt.Issuer{Keys: ring, Name: s.Cfg.Issuer, Audiences: s.Cfg.Audiences}
+	token, _, err := issuer.NewClientAccess(clientID, scopes, s.Cfg.TokenTTL)
+
+	return token, err
+}
+
+// WithToken returns context sending the access token in "authorization"
+// metadata of requests
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
@@ -0,0 +1,46 @@
+package suite
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// Notification is a message written by the file notifier of the service
+type Notification struct {
+	Kind    string    `json:"kind"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	Secret  string    `json:"secret"`
+	Time    time.Time `json:"time"`
+}
+
+// LastNotification returns the latest notification of the kind sent to the
+// address. Notifications are read from the file configured for the service
+func (s *SuiteAuth) LastNotification(to, kind string) (Notification, bool) {
+	f, err := os.Open(s.Cfg.Notifier.Path)
+	if err != nil {
+		return Notification{}, false
+	}
+	defer f.Close()
+
+	var (
+		res   Notification
+		found bool
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var n Notification
+		if err = json.Unmarshal(scanner.Bytes(), &n); err != nil {
+			continue
+		}
+
+		if n.To == to && n.Kind == kind {
+			res, found = n, true
+		}
+	}
+
+	return res, found
+}
@@ -0,0 +1,219 @@
+package suite
+
+import (
+	"Service/internal/app"
+	"Service/internal/config"
+	"Service/internal/lib/password"
+	"Service/internal/storage/sqlite"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	OAuthClientID    = "test-app"
+	OAuthOtherClient = "other-app"
+	OAuthRedirectURI = "https://app.example/callback"
+	OAuthServiceID   = "test-service"
+)
+
+// SuiteOAuth runs HTTP application of the service in-process with its own
+// storage and test clients registered. ServiceSecret is the secret of the
+// confidential client OAuthServiceID
+type SuiteOAuth struct {
+	*testing.T
+	Cfg           *config.Config
+	Server        *httptest.Server
+	Client        *http.Client
+	Storage       *sqlite.Storage
+	ServiceSecret string
+}
+
+func NewSuiteOAuth(t *testing.T, cfg *config.Config) *SuiteOAuth {
+	t.Helper()
+
+	hasher, err := password.NewHasher(password.Argon2idParams(cfg.Password.Argon2id))
+	if err != nil {
+		t.Fatalf("failed to create hasher: %v", err)
+	}
+	secret := rand.Text()
+	secretHash, err := hasher.Hash(secret)
+	if err != nil {
+		t.Fatalf("failed to hash secret: %v", err)
+	}
+
+	local := *cfg
+	local.StoragePath = filepath.Join(t.TempDir(), "auth.db")
+	local.Notifier.Path = filepath.Join(t.TempDir(), "outbox.jsonl")
+	local.OAuth.Clients = []config.OAuthClientObj{
+		{
+			ID:           OAuthClientID,
+			Name:         "Test App",
+			RedirectURIs: []string{OAuthRedirectURI},
+			Scopes:       []string{"openid", "profile", "email"},
+		},
+		{
+			ID:           OAuthOtherClient,
+			RedirectURIs: []string{"https://other.example/callback"},
+			Scopes:       []string{"profile"},
+		},
+		{
+			ID:         OAuthServiceID,
+			Grants:     []string{"client_credentials"},
+			Scopes:     []string{"users:read", "users:write"},
+			SecretHash: string(secretHash),
+		},
+	}
+
+	application := app.New(slog.New(slog.NewTextHandler(io.Discard, nil)), &local)
+	srv := httptest.NewServer(application.HTTPApp.Handler())
+	t.Cleanup(srv.Close)
+
+	return &SuiteOAuth{
+		T:      t,
+		Cfg:    &local,
+		Server: srv,
+		Client: &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Storage:       sqlite.New(local.StoragePath),
+		ServiceSecret: secret,
+	}
+}
+
+// SignUp stores new user with the password
+func (s *SuiteOAuth) SignUp(login, email, pass string) uint64 {
+	s.Helper()
+
+	hasher, err := password.NewHasher(password.Argon2idParams(s.Cfg.Password.Argon2id))
+	if err != nil {
+		s.Fatalf("failed to create hasher: %v", err)
+	}
+
+	hash, err := hasher.Hash(pass)
+	if err != nil {
+		s.Fatalf("failed to hash password: %v", err)
+	}
+
+	uuid, err := s.Storage.Save(s.Context(), login, email, hash)
+	if err != nil {
+		s.Fatalf("failed to save user: %v", err)
+	}
+
+	return uuid
+}
+
+// Get sends GET request to the path
+func (s *SuiteOAuth) Get(path string, query url.Values) *http.Response {
+	s.Helper()
+
+	resp, err := s.Client.Get(s.Server.URL + path + "?" + query.Encode())
+	if err != nil {
+		s.Fatalf("failed to send request: %v", err)
+	}
+	s.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+// PostForm sends the form to the path
+func (s *SuiteOAuth) PostForm(path string, form url.Values) *http.Response {
+	s.Helper()
+
+	resp, err := s.Client.Post(
+		s.Server.URL+path,
+		"application/x-www-form-urlencoded",
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		s.Fatalf("failed to send request: %v", err)
+	}
+	s.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+// Token posts the form to the token endpoint and decodes JSON response
+func (s *SuiteOAuth) Token(form url.Values) (int, map[string]any) {
+	s.Helper()
+
+	return s.decodeToken(s.PostForm("/token", form))
+}
+
+// TokenWithBasic posts the form to the token endpoint authenticating the
+// client with HTTP Basic scheme
+func (s *SuiteOAuth) TokenWithBasic(id, secret string, form url.Values) (int, map[string]any) {
+	s.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, s.Server.URL+"/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		s.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(id), url.QueryEscape(secret))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		s.Fatalf("failed to send request: %v", err)
+	}
+	s.Cleanup(func() { resp.Body.Close() })
+
+	return s.decodeToken(resp)
+}
+
+// UserInfo requests userinfo endpoint with the bearer access token and
+// decodes JSON response of successful requests
+func (s *SuiteOAuth) UserInfo(token string) (*http.Response, map[string]any) {
+	s.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, s.Server.URL+"/userinfo", nil)
+	if err != nil {
+		s.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		s.Fatalf("failed to send request: %v", err)
+	}
+	s.Cleanup(func() { resp.Body.Close() })
+
+	body := make(map[string]any)
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			s.Fatalf("failed to decode userinfo response: %v", err)
+		}
+	}
+
+	return resp, body
+}
+
+func (s *SuiteOAuth) decodeToken(resp *http.Response) (int, map[string]any) {
+	s.Helper()
+
+	body := make(map[string]any)
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		s.Fatalf("failed to decode token response: %v", err)
+	}
+
+	return resp.StatusCode, body
+}
+
+// NewPKCE returns random code verifier and its S256 challenge
+func NewPKCE() (verifier, challenge string) {
+	verifier = rand.Text() + rand.Text()
+	sum := sha256.Sum256([]byte(verifier))
+
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package suite
+
+import (
+	"Service/internal/config"
+	"context"
+	rbacv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/rbac"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"net"
+	"strconv"
+	"testing"
+)
+
+type SuiteRBAC struct {
+	*testing.T
+	Cfg    *config.Config
+	Client rbacv1.RBACClient
+}
+
+func NewSuiteRBAC(t *testing.T, cfg *config.Config) (context.Context, *SuiteRBAC) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		t.Helper()
+		cancel()
+	})
+
+	srvAddr := net.JoinHostPort("localhost", strconv.Itoa(cfg.GRPC.Port))
+	cc, err := grpc.NewClient(
+		srvAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to connect to grpc server: %v", err)
+	}
+
+	client := rbacv1.NewRBACClient(cc)
+	return ctx, &SuiteRBAC{
+		Client: client,
+		Cfg:    cfg,
+	}
+}
@@ -32,22 +32,29 @@ func TestUsersByLogin(t *testing.T) {
 		},
 	}
 
+	var token string
 	for _, test := range tt {
-		_, err := sta.Client.SignUp(ctx, &authv1.SignUpRequest{
+		resp, err := sta.Client.SignUp(ctx, &authv1.SignUpRequest{
 			Login:    test.login,
 			Email:    test.email,
 			Password: test.password,
 		})
 		require.NoError(t, err, "failed to sign up user")
+		token = resp.GetAccessToken()
 	}
 
-	users, err := stu.Client.UsersByLogin(ctx, &userinfov1.UsersByLoginRequest{
+	users, err := stu.Client.UsersByLogin(suite.WithToken(ctx, token), &userinfov1.UsersByLoginRequest{
 		Login: "_",
 	})
 	require.NoError(t, err)
 
+	// emails are shown only to their owner, which is the last signed up user
 	for i, user := range users.GetUsers() {
 		require.Equal(t, tt[i].login, user.Login)
-		require.Equal(t, tt[i].email, user.Email)
+		if i == len(tt)-1 {
+			require.Equal(t, tt[i].email, user.Email)
+		} else {
+			require.Empty(t, user.Email)
+		}
 	}
 }
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"Service/internal/config"
+	ssojwt "Service/internal/lib/jwt"
+	"Service/tests/suite"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	authv1 "github.com/IlianBuh/SSO_Protobuf/gen/go/auth"
+	"github.com/brianvoe/gofakeit"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKSVerifiesIssuedTokens(t *testing.T) {
+	cfg := config.New()
+	ctx, st := suite.NewSuiteAuth(t, cfg)
+
+	resp, err := st.Client.SignUp(ctx, &authv1.SignUpRequest{
+		Login:    gofakeit.FirstName() + gofakeit.LastName(),
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	httpResp, err := http.Get(fmt.Sprintf("http://localhost:%d/.well-known/jwks.json", cfg.HTTP.Port))
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+	require.Equal(t, http.StatusOK, httpResp.StatusCode)
+	assert.NotEmpty(t, httpResp.Header.Get("Cache-Control"))
+	assert.NotEmpty(t, httpResp.Header.Get("ETag"))
+
+	var set ssojwt.JWKSet
+	require.NoError(t, json.NewDecoder(httpResp.Body).Decode(&set))
+
+	token, _, err := jwt.NewParser().ParseUnverified(resp.GetAccessToken(), jwt.MapClaims{})
+	require.NoError(t, err)
+	kid, _ := token.Header["kid"].(string)
+
+	var published *ssojwt.JWK
+	for _, k := range set.Keys {
+		if k.Kid == kid {
+			published = &k
+		}
+	}
+	require.NotNil(t, published, "signing key is not published")
+
+	for _, k := range cfg.Keys {
+		if k.ID != kid {
+			continue
+		}
+
+		key, err := ssojwt.LoadKey(k.ID, k.Path, k.ActiveFrom)
+		require.NoError(t, err)
+		assert.Equal(t, key.JWK(), *published)
+	}
+}